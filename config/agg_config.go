@@ -166,7 +166,7 @@ func isChangedAggIntfLagType(change *diff.Change) bool {
 func (this *ConfigMngrT) validateSetAggIntfMemberChange(changeItem *DiffChangeMgmtT, changelog *DiffChangelogMgmtT) error {
 	ifname := changeItem.Change.Path[cmd.AggIntfIfnamePathItemIdxC]
 	if !this.isEthIntfAvailable(ifname) {
-		return fmt.Errorf("Ethernet interface %s is not available", ifname)
+		return newValidationErr(ValidationReasonUnavailableC, fmt.Errorf("Ethernet interface %s is not available", ifname))
 	}
 
 	aggIfname, err := utils.ConvertGoInterfaceIntoString(changeItem.Change.To)
@@ -177,8 +177,8 @@ func (this *ConfigMngrT) validateSetAggIntfMemberChange(changeItem *DiffChangeMg
 	log.Infof("Requested add Ethernet interface %s as LAG member %s", ifname, aggIfname)
 	setAggIntfMemberCmd := cmd.NewSetAggIntfMemberCmdT(changeItem.Change, this.ethSwitchMgmtClient)
 	if err := this.transConfigLookupTbl.checkDependenciesForSetAggIntfMember(aggIfname, ifname); err != nil {
-		return fmt.Errorf("Cannot %q because there are dependencies from interface %s:\n%s",
-			setAggIntfMemberCmd.GetName(), ifname, err)
+		return newValidationErr(ValidationReasonDependencyC, fmt.Errorf("Cannot %q because there are dependencies from interface %s:\n%s",
+			setAggIntfMemberCmd.GetName(), ifname, err))
 	}
 
 	if this.transHasBeenStarted {
@@ -200,7 +200,7 @@ func (this *ConfigMngrT) validateSetAggIntfMemberChange(changeItem *DiffChangeMg
 func (this *ConfigMngrT) validateDeleteAggIntfMemberChange(changeItem *DiffChangeMgmtT, changelog *DiffChangelogMgmtT) error {
 	ifname := changeItem.Change.Path[cmd.AggIntfIfnamePathItemIdxC]
 	if !this.isEthIntfAvailable(ifname) {
-		return fmt.Errorf("Ethernet interface %s is not available", ifname)
+		return newValidationErr(ValidationReasonUnavailableC, fmt.Errorf("Ethernet interface %s is not available", ifname))
 	}
 
 	aggIfname, err := utils.ConvertGoInterfaceIntoString(changeItem.Change.From)
@@ -232,8 +232,8 @@ func (this *ConfigMngrT) validateDeleteAggIntfMemberChange(changeItem *DiffChang
 	log.Infof("Requested remove Ethernet interface %s from LAG member %s", ifname, aggIfname)
 	deleteAggIntfMemberCmd := cmd.NewDeleteAggIntfMemberCmdT(changeItem.Change, this.ethSwitchMgmtClient)
 	if err := this.transConfigLookupTbl.checkDependenciesForDeleteAggIntfMember(aggIfname, ifname); err != nil {
-		return fmt.Errorf("Cannot %q because there are dependencies from interface %s:\n%s",
-			deleteAggIntfMemberCmd.GetName(), ifname, err)
+		return newValidationErr(ValidationReasonDependencyC, fmt.Errorf("Cannot %q because there are dependencies from interface %s:\n%s",
+			deleteAggIntfMemberCmd.GetName(), ifname, err))
 	}
 
 	if this.transHasBeenStarted {
@@ -260,7 +260,12 @@ func (this *ConfigMngrT) validateSetAggIntfLagTypeChange(changeItem *DiffChangeM
 	aggIfname := changeItem.Change.Path[cmd.AggIntfIfnamePathItemIdxC]
 	log.Infof("Requested set aggregate interface LAG type %s", aggIfname)
 	if changeItem.Change.Type == diff.UPDATE {
-		return fmt.Errorf("Dependency error: Transitions LAG type between LACP and STATIC for aggregate interface %s is not supported. Please re-create current aggregate interface", aggIfname)
+		// Aggregate interface is kept on transition between LACP and STATIC type. LACP is enabled
+		// or disabled by change of LACP interface, which has to be done in the same transaction.
+		device := (*this.transCandidateConfig).(*oc.Device)
+		if err := checkLacpPairing(device, aggIfname); err != nil {
+			return err
+		}
 	}
 
 	changeItem.MarkAsProcessed()
@@ -287,22 +292,9 @@ func (this *ConfigMngrT) validateSetAggIntfChange(changeItem *DiffChangeMgmtT, c
 		return fmt.Errorf("Cannot find aggregation settings for aggregate interface %s in config", aggIfname)
 	}
 
-	if aggregation.LagType == oc.OpenconfigIfAggregate_AggregationType_UNSET {
-		return fmt.Errorf("LAG type for aggregate interface %s cannot be unset", aggIfname)
-	} else if aggregation.LagType == oc.OpenconfigIfAggregate_AggregationType_LACP {
-		lacp := device.GetLacp()
-		if lacp == nil {
-			return fmt.Errorf("Cannot find LACP configuration which is required for aggregate interface %s as LACP type", aggIfname)
-		}
-
-		lacpIntf := lacp.GetInterface(aggIfname)
-		if lacpIntf == nil {
-			return fmt.Errorf("Cannot find in config corresponding LACP to aggregate interface %s", aggIfname)
-		}
-
-		// Delegate to create aggregate interface to LACP config module
-		// changeItem.MarkAsProcessed()
-		// return nil
+	// LACP of aggregate interface is enabled by LACP config module
+	if err := checkLacpPairing(device, aggIfname); err != nil {
+		return err
 	}
 
 	lagTypeChange, err := this.findAggIntfLagTypeFromChangelog(aggIfname, changelog)
@@ -312,8 +304,8 @@ func (this *ConfigMngrT) validateSetAggIntfChange(changeItem *DiffChangeMgmtT, c
 
 	setAggIntfCmd := cmd.NewSetAggIntfCmdT(changeItem.Change, lagTypeChange.Change, this.ethSwitchMgmtClient)
 	if err := this.transConfigLookupTbl.checkDependenciesForSetAggIntf(aggIfname); err != nil {
-		return fmt.Errorf("Cannot %q because there are dependencies from LAG interface %s:\n%s",
-			setAggIntfCmd.GetName(), aggIfname, err)
+		return newValidationErr(ValidationReasonDependencyC, fmt.Errorf("Cannot %q because there are dependencies from LAG interface %s:\n%s",
+			setAggIntfCmd.GetName(), aggIfname, err))
 	}
 
 	if this.transHasBeenStarted {
@@ -360,10 +352,15 @@ func (this *ConfigMngrT) validateDeleteAggIntfChange(changeItem *DiffChangeMgmtT
 	}
 
 	log.Infof("Requested delete LAG interface %s", aggIfname)
+	if !this.isLacpDeleted(aggIfname) {
+		return newValidationErr(ValidationReasonDependencyC, fmt.Errorf("Cannot delete aggregate interface %s, because its LACP interface is not deleted",
+			aggIfname))
+	}
+
 	deleteAggIntfCmd := cmd.NewDeleteAggIntfCmdT(changeItem.Change, this.ethSwitchMgmtClient)
 	if err := this.transConfigLookupTbl.checkDependenciesForDeleteAggIntf(aggIfname); err != nil {
-		return fmt.Errorf("Cannot %q because there are dependencies from LAG interface %s:\n%s",
-			deleteAggIntfCmd.GetName(), aggIfname, err)
+		return newValidationErr(ValidationReasonDependencyC, fmt.Errorf("Cannot %q because there are dependencies from LAG interface %s:\n%s",
+			deleteAggIntfCmd.GetName(), aggIfname, err))
 	}
 
 	if this.transHasBeenStarted {
@@ -470,7 +467,7 @@ func (this *ConfigMngrT) processSetAggIntfMemberFromChangelog(changelog *DiffCha
 		// Repeat till there is not any change related to set LAG interface member
 		if change, exists := findSetAggIntfMemberChange(changelog); exists {
 			if err := this.validateSetAggIntfMemberChange(change, changelog); err != nil {
-				return err
+				return newChangeValidationErr(change, err)
 			}
 		} else {
 			break
@@ -489,7 +486,7 @@ func (this *ConfigMngrT) processDeleteAggIntfMemberFromChangelog(changelog *Diff
 		// Repeat till there is not any change related to delete LAG interface member
 		if change, exists := findDeleteAggIntfMemberChange(changelog); exists {
 			if err := this.validateDeleteAggIntfMemberChange(change, changelog); err != nil {
-				return err
+				return newChangeValidationErr(change, err)
 			}
 		} else {
 			break
@@ -508,7 +505,7 @@ func (this *ConfigMngrT) processSetAggIntfLagTypeFromChangelog(changelog *DiffCh
 		// Repeat till there is not any change related to set aggregate interface LAG type
 		if change, exists := findSetAggIntfLagTypeChange(changelog); exists {
 			if err := this.validateSetAggIntfLagTypeChange(change, changelog); err != nil {
-				return err
+				return newChangeValidationErr(change, err)
 			}
 		} else {
 			break
@@ -527,7 +524,7 @@ func (this *ConfigMngrT) processSetAggIntfFromChangelog(changelog *DiffChangelog
 		// Repeat till there is not any change related to set LAG interface
 		if change, exists := findSetAggIntfChange(changelog); exists {
 			if err := this.validateSetAggIntfChange(change, changelog); err != nil {
-				return err
+				return newChangeValidationErr(change, err)
 			}
 		} else {
 			break
@@ -546,7 +543,7 @@ func (this *ConfigMngrT) processDeleteAggIntfFromChangelog(changelog *DiffChange
 		// Repeat till there is not any change related to delete LAG interface
 		if change, exists := findDeleteAggIntfChange(changelog); exists {
 			if err := this.validateDeleteAggIntfChange(change, changelog); err != nil {
-				return err
+				return newChangeValidationErr(change, err)
 			}
 		} else {
 			break
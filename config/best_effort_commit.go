@@ -0,0 +1,391 @@
+package config
+
+import (
+	"fmt"
+	cmd "opennos-mgmt/config/command"
+	"opennos-mgmt/gnmi"
+	"opennos-mgmt/gnmi/modeldata/oc"
+	"sort"
+	"strings"
+
+	log "github.com/golang/glog"
+	"github.com/openconfig/ygot/ygot"
+	"github.com/r3labs/diff"
+)
+
+// configRootT is entry of list of device config, i.e. interface or component, changed by command
+type configRootT struct {
+	list string
+	name string
+}
+
+// cmdGroupT is group of commands, which change interfaces independent of other groups
+type cmdGroupT struct {
+	roots map[configRootT]bool
+	cmds  []cmd.CommandI
+}
+
+func newCmdGroup() *cmdGroupT {
+	return &cmdGroupT{
+		roots: make(map[configRootT]bool),
+		cmds:  make([]cmd.CommandI, 0),
+	}
+}
+
+func (this *cmdGroupT) names() []string {
+	unique := make(map[string]bool, len(this.roots))
+	for root := range this.roots {
+		unique[root.name] = true
+	}
+
+	names := make([]string, 0, len(unique))
+	for name := range unique {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+func (this *cmdGroupT) cmdNames() []string {
+	names := make([]string, 0, len(this.cmds))
+	for _, command := range this.cmds {
+		names = append(names, command.GetName())
+	}
+
+	return names
+}
+
+// execute runs commands of group. If any of them fails, commands of group executed so far are undone.
+func (this *cmdGroupT) execute() error {
+	for i, command := range this.cmds {
+		log.Infof("Execute command %q", command.GetName())
+		if err := command.Execute(); err != nil {
+			this.undo(i)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// undo reverts the first count of commands of group in reverse order
+func (this *cmdGroupT) undo(count int) {
+	for i := count - 1; i >= 0; i-- {
+		log.Infof("Undo command %q", this.cmds[i].GetName())
+		if err := this.cmds[i].Undo(); err != nil {
+			log.Errorf("Failed to undo command %q: %s", this.cmds[i].GetName(), err)
+		}
+	}
+}
+
+func changeValueToString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case *string:
+		if v != nil {
+			return *v
+		}
+	}
+
+	return ""
+}
+
+// findCmdConfigRoots returns entries of config changed by command. Member of aggregate interface depends on
+// the aggregate interface, so both of them are returned. LACP interface is named after its aggregate
+// interface, so they share the group.
+func findCmdConfigRoots(command cmd.CommandI) ([]configRootT, error) {
+	changes := command.GetChanges()
+	if len(changes) == 0 {
+		return nil, fmt.Errorf("Command %q has no changes of config", command.GetName())
+	}
+
+	roots := make([]configRootT, 0, len(changes))
+	for _, ch := range changes {
+		if (len(ch.Path) > cmd.LacpIfnamePathItemIdxC) && (ch.Path[cmd.LacpPathItemIdxC] == cmd.LacpPathItemC) {
+			roots = append(roots, configRootT{list: cmd.LacpPathItemC, name: ch.Path[cmd.LacpIfnamePathItemIdxC]})
+			continue
+		}
+
+		if len(ch.Path) <= cmd.EthIntfIfnamePathItemIdxC {
+			return nil, fmt.Errorf("Cannot find entry of config changed by command %q", command.GetName())
+		}
+
+		list := ch.Path[cmd.EthIntfInterfacePathItemIdxC]
+		if (list != cmd.EthIntfInterfacePathItemC) && (list != cmd.PortBreakoutCompPathItemC) {
+			return nil, fmt.Errorf("Command %q changes %s, which is not interface nor component", command.GetName(), list)
+		}
+
+		roots = append(roots, configRootT{list: list, name: ch.Path[cmd.EthIntfIfnamePathItemIdxC]})
+		if isAggIntfMemberChange(ch) {
+			for _, value := range []interface{}{ch.From, ch.To} {
+				if aggIfname := changeValueToString(value); aggIfname != "" {
+					roots = append(roots, configRootT{list: cmd.AggIntfInterfacePathItemC, name: aggIfname})
+				}
+			}
+		}
+	}
+
+	return roots, nil
+}
+
+func isAggIntfMemberChange(change *diff.Change) bool {
+	if len(change.Path) != cmd.AggIntfMemberPathItemsCountC {
+		return false
+	}
+
+	return (change.Path[cmd.AggIntfMemberEthernetPathItemIdxC] == cmd.AggIntfMemberEthernetPathItemC) &&
+		(change.Path[cmd.AggIntfMemberAggIdPathItemIdxC] == cmd.AggIntfMemberAggIdPathItemC)
+}
+
+// groupKeyOfConfigRoot returns key, which is shared by all entries of config depending on each other.
+// Ports created by breakout depend on the master port.
+func groupKeyOfConfigRoot(root configRootT) string {
+	if masterPort, isBreakout := getBreakoutMasterPort(root.name); isBreakout {
+		return masterPort
+	}
+
+	return root.name
+}
+
+// groupTransCmds splits commands of transaction into groups changing independent interfaces. Commands
+// keep order of transaction within group. If any command cannot be assigned to interface, all commands
+// are put into the single group.
+func (this *ConfigMngrT) groupTransCmds() []*cmdGroupT {
+	parentByKey := make(map[string]string)
+	var findKey func(key string) string
+	findKey = func(key string) string {
+		parent, exists := parentByKey[key]
+		if !exists || parent == key {
+			parentByKey[key] = key
+			return key
+		}
+
+		root := findKey(parent)
+		parentByKey[key] = root
+		return root
+	}
+
+	rootsByCmd := make([][]configRootT, 0, this.transCmdList.Len())
+	for e := this.transCmdList.Front(); e != nil; e = e.Next() {
+		command := e.Value.(cmd.CommandI)
+		roots, err := findCmdConfigRoots(command)
+		if err != nil {
+			log.Infof("Commands are committed as the single group: %s", err)
+			return []*cmdGroupT{this.singleCmdGroup()}
+		}
+
+		for _, root := range roots[1:] {
+			parentByKey[findKey(groupKeyOfConfigRoot(root))] = findKey(groupKeyOfConfigRoot(roots[0]))
+		}
+		rootsByCmd = append(rootsByCmd, roots)
+	}
+
+	groups := make([]*cmdGroupT, 0)
+	groupByKey := make(map[string]*cmdGroupT)
+	i := 0
+	for e := this.transCmdList.Front(); e != nil; e = e.Next() {
+		roots := rootsByCmd[i]
+		i++
+		key := findKey(groupKeyOfConfigRoot(roots[0]))
+		group, exists := groupByKey[key]
+		if !exists {
+			group = newCmdGroup()
+			groupByKey[key] = group
+			groups = append(groups, group)
+		}
+
+		group.cmds = append(group.cmds, e.Value.(cmd.CommandI))
+		for _, root := range roots {
+			group.roots[root] = true
+		}
+	}
+
+	return groups
+}
+
+func (this *ConfigMngrT) singleCmdGroup() *cmdGroupT {
+	group := newCmdGroup()
+	for e := this.transCmdList.Front(); e != nil; e = e.Next() {
+		command := e.Value.(cmd.CommandI)
+		group.cmds = append(group.cmds, command)
+		if roots, err := findCmdConfigRoots(command); err == nil {
+			for _, root := range roots {
+				group.roots[root] = true
+			}
+		}
+	}
+
+	return group
+}
+
+// commitBestEffort executes commands of transaction group by group. Changes of failed groups are
+// withdrawn from device and candidate config, while changes of other groups are committed. Report
+// of groups is returned, unless all of groups have failed. If changes of failed groups cannot be
+// withdrawn from candidate config, the whole commit fails and changes of other groups are undone.
+func (this *ConfigMngrT) commitBestEffort(candidateConfig *ygot.ValidatedGoStruct) (*gnmi.BestEffortResult, error) {
+	groups := this.groupTransCmds()
+	result := &gnmi.BestEffortResult{
+		Groups: make([]gnmi.CommitGroupResult, 0, len(groups)),
+	}
+
+	appliedGroups := make([]*cmdGroupT, 0, len(groups))
+	failedRoots := make(map[configRootT]bool)
+	var lastErr error
+	for _, group := range groups {
+		groupResult := gnmi.CommitGroupResult{
+			Interfaces: group.names(),
+			Commands:   group.cmdNames(),
+		}
+
+		if err := group.execute(); err != nil {
+			log.Errorf("Failed to commit changes of %s: %s", strings.Join(groupResult.Interfaces, ", "), err)
+			groupResult.Error = err.Error()
+			for root := range group.roots {
+				failedRoots[root] = true
+			}
+			lastErr = err
+		} else {
+			appliedGroups = append(appliedGroups, group)
+		}
+		result.Groups = append(result.Groups, groupResult)
+	}
+
+	if result.FailedGroups() == len(result.Groups) && lastErr != nil {
+		this.discardOrFinishTrans()
+		return nil, lastErr
+	}
+
+	if len(failedRoots) == 0 {
+		this.configLookupTbl = this.transConfigLookupTbl.makeCopy()
+	}
+
+	this.discardOrFinishTrans()
+	if len(failedRoots) > 0 {
+		if err := this.withdrawFailedChanges(candidateConfig, failedRoots); err != nil {
+			log.Errorf("Failed to withdraw changes of failed groups: %s", err)
+			undoCmdGroups(appliedGroups)
+			return nil, err
+		}
+	}
+
+	log.Infof("Save new config, %d of %d groups of changes have failed", result.FailedGroups(), len(result.Groups))
+	if err := this.commitCandidateConfig(candidateConfig); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// undoCmdGroups reverts changes of groups in reverse order
+func undoCmdGroups(groups []*cmdGroupT) {
+	for i := len(groups) - 1; i >= 0; i-- {
+		groups[i].undo(len(groups[i].cmds))
+	}
+}
+
+// withdrawFailedChanges restores entries of running config, which have been changed by failed groups, in
+// candidate config. Lookup tables of config are rebuilt for the candidate config, because they have to
+// track changes of committed groups only.
+func (this *ConfigMngrT) withdrawFailedChanges(candidateConfig *ygot.ValidatedGoStruct, failedRoots map[configRootT]bool) error {
+	device := (*candidateConfig).(*oc.Device)
+	runningDevice := this.runningConfig.(*oc.Device)
+	for root := range failedRoots {
+		switch root.list {
+		case cmd.EthIntfInterfacePathItemC:
+			intf, exists := runningDevice.Interface[root.name]
+			if !exists {
+				delete(device.Interface, root.name)
+				continue
+			}
+
+			intfCopy, err := ygot.DeepCopy(intf)
+			if err != nil {
+				return err
+			}
+
+			if device.Interface == nil {
+				device.Interface = make(map[string]*oc.Interface)
+			}
+			device.Interface[root.name] = intfCopy.(*oc.Interface)
+		case cmd.PortBreakoutCompPathItemC:
+			component, exists := runningDevice.Component[root.name]
+			if !exists {
+				delete(device.Component, root.name)
+				continue
+			}
+
+			componentCopy, err := ygot.DeepCopy(component)
+			if err != nil {
+				return err
+			}
+
+			if device.Component == nil {
+				device.Component = make(map[string]*oc.Component)
+			}
+			device.Component[root.name] = componentCopy.(*oc.Component)
+		case cmd.LacpPathItemC:
+			lacpIntf := runningDevice.GetLacp().GetInterface(root.name)
+			if lacpIntf == nil {
+				if device.Lacp != nil {
+					delete(device.Lacp.Interface, root.name)
+				}
+				continue
+			}
+
+			lacpIntfCopy, err := ygot.DeepCopy(lacpIntf)
+			if err != nil {
+				return err
+			}
+
+			lacp := device.GetOrCreateLacp()
+			if lacp.Interface == nil {
+				lacp.Interface = make(map[string]*oc.Lacp_Interface)
+			}
+			lacp.Interface[root.name] = lacpIntfCopy.(*oc.Lacp_Interface)
+		default:
+			return fmt.Errorf("Cannot withdraw changes of %s %s", root.list, root.name)
+		}
+	}
+
+	if err := this.rebuildConfigLookupTbl(candidateConfig); err != nil {
+		return fmt.Errorf("Failed to rebuild lookup tables of config: %s", err)
+	}
+
+	return nil
+}
+
+// rebuildConfigLookupTbl updates lookup tables with changes of candidate config. Lookup tables of transaction
+// contain changes of failed groups too, so they cannot be used.
+func (this *ConfigMngrT) rebuildConfigLookupTbl(candidateConfig *ygot.ValidatedGoStruct) error {
+	changelog, err := this.diffRunningConfigWithCandidateConfig(candidateConfig)
+	if err != nil {
+		return err
+	}
+
+	diffChangelog, err := this.extendChangelog(&changelog)
+	if err != nil {
+		return err
+	}
+
+	if err = this.markTransParamChangesAsProcessed(diffChangelog); err != nil {
+		return err
+	}
+
+	// Commands are not queued, because transaction has been already finished
+	this.initTransCmds()
+	this.transCandidateConfig = candidateConfig
+	defer func() {
+		this.transConfigLookupTbl = nil
+		this.transCandidateConfig = nil
+		this.transCmdList.Init()
+	}()
+
+	if err = this.parseChangelogAndConvertToCommands(diffChangelog); err != nil {
+		return err
+	}
+
+	this.configLookupTbl = this.transConfigLookupTbl.makeCopy()
+	return nil
+}
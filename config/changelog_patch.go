@@ -0,0 +1,263 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/openconfig/ygot/ygot"
+	"github.com/r3labs/diff"
+)
+
+// applyChangelog applies changes of changelog onto config. Every node changed by changelog has to
+// have in config either the same value as the one, which has been changed, or the value requested
+// by change. Otherwise the change conflicts with config, which has changed in the meantime.
+func applyChangelog(changelog diff.Changelog, config ygot.GoStruct) error {
+	for i := 0; i < len(changelog); i++ {
+		change := &changelog[i]
+		if err := applyChange(change, reflect.ValueOf(config), change.Path); err != nil {
+			return fmt.Errorf("Cannot apply change of %s: %s", strings.Join(change.Path, "/"), err)
+		}
+	}
+
+	return nil
+}
+
+func applyChange(change *diff.Change, node reflect.Value, path []string) error {
+	if len(path) == 0 {
+		return setChangedValue(change, node)
+	}
+
+	if node.Kind() == reflect.Ptr {
+		if node.IsNil() {
+			// Node is already gone
+			if change.Type == diff.DELETE {
+				return nil
+			}
+
+			node.Set(reflect.New(node.Type().Elem()))
+		}
+		node = node.Elem()
+	}
+
+	switch node.Kind() {
+	case reflect.Struct:
+		field := node.FieldByName(path[0])
+		if !field.IsValid() {
+			return fmt.Errorf("There is no field %s in %s", path[0], node.Type())
+		}
+
+		return applyChange(change, field, path[1:])
+	case reflect.Map:
+		return applyMapChange(change, node, path)
+	case reflect.Slice:
+		if len(path) != 1 {
+			return fmt.Errorf("Unsupported change of entry of list %s", node.Type())
+		}
+
+		return applyLeafListChange(change, node)
+	default:
+		return fmt.Errorf("Unsupported change of node %s", node.Type())
+	}
+}
+
+func applyMapChange(change *diff.Change, node reflect.Value, path []string) error {
+	key, err := mapKeyOfChangePath(node, path[0])
+	if err != nil {
+		return err
+	}
+
+	entry := node.MapIndex(key)
+	if len(path) == 1 {
+		if err = checkChangedValue(change, entry); err != nil {
+			return err
+		}
+
+		if change.To == nil {
+			if !node.IsNil() {
+				node.SetMapIndex(key, reflect.Value{})
+			}
+			return nil
+		}
+
+		value, err := changeValueOf(change.To, node.Type().Elem())
+		if err != nil {
+			return err
+		}
+
+		if node.IsNil() {
+			node.Set(reflect.MakeMap(node.Type()))
+		}
+		node.SetMapIndex(key, value)
+		return nil
+	}
+
+	if node.Type().Elem().Kind() != reflect.Ptr {
+		return fmt.Errorf("Unsupported change of entry of map %s", node.Type())
+	}
+
+	if !entry.IsValid() || entry.IsNil() {
+		// Entry is already gone
+		if change.Type == diff.DELETE {
+			return nil
+		}
+
+		if node.IsNil() {
+			node.Set(reflect.MakeMap(node.Type()))
+		}
+		entry = reflect.New(node.Type().Elem().Elem())
+		node.SetMapIndex(key, entry)
+	}
+
+	return applyChange(change, entry, path[1:])
+}
+
+func applyLeafListChange(change *diff.Change, node reflect.Value) error {
+	indexOf := func(value interface{}) int {
+		for i := 0; i < node.Len(); i++ {
+			if reflect.DeepEqual(node.Index(i).Interface(), value) {
+				return i
+			}
+		}
+		return -1
+	}
+
+	switch change.Type {
+	case diff.CREATE:
+		if indexOf(change.To) >= 0 {
+			return nil
+		}
+
+		value, err := changeValueOf(change.To, node.Type().Elem())
+		if err != nil {
+			return err
+		}
+
+		node.Set(reflect.Append(node, value))
+	case diff.DELETE:
+		if i := indexOf(change.From); i >= 0 {
+			node.Set(reflect.AppendSlice(node.Slice(0, i), node.Slice(i+1, node.Len())))
+		}
+	default:
+		i := indexOf(change.From)
+		if i < 0 {
+			if indexOf(change.To) >= 0 {
+				return nil
+			}
+
+			return fmt.Errorf("Value %v has changed in the meantime", change.From)
+		}
+
+		value, err := changeValueOf(change.To, node.Type().Elem())
+		if err != nil {
+			return err
+		}
+
+		node.Index(i).Set(value)
+	}
+
+	return nil
+}
+
+func setChangedValue(change *diff.Change, node reflect.Value) error {
+	if err := checkChangedValue(change, node); err != nil {
+		return err
+	}
+
+	if change.To == nil {
+		node.Set(reflect.Zero(node.Type()))
+		return nil
+	}
+
+	value, err := changeValueOf(change.To, node.Type())
+	if err != nil {
+		return err
+	}
+
+	node.Set(value)
+	return nil
+}
+
+func checkChangedValue(change *diff.Change, node reflect.Value) error {
+	current := changedValueOf(node)
+	if reflect.DeepEqual(current, changedValueOf(reflect.ValueOf(change.From))) ||
+		reflect.DeepEqual(current, changedValueOf(reflect.ValueOf(change.To))) {
+		return nil
+	}
+
+	return fmt.Errorf("Value %v has changed in the meantime to %v", change.From, current)
+}
+
+// changedValueOf returns value of node in the same form as values of changes are, i.e. value of
+// leaf instead of pointer to it and nil instead of unset node
+func changedValueOf(node reflect.Value) interface{} {
+	if !node.IsValid() {
+		return nil
+	}
+
+	switch node.Kind() {
+	case reflect.Ptr:
+		if node.IsNil() {
+			return nil
+		}
+		if node.Elem().Kind() != reflect.Struct {
+			return node.Elem().Interface()
+		}
+	case reflect.Interface, reflect.Map, reflect.Slice:
+		if node.IsNil() {
+			return nil
+		}
+	}
+
+	if node.IsZero() {
+		return nil
+	}
+
+	return node.Interface()
+}
+
+// changeValueOf converts value of change into value, which can be set to node of given type
+func changeValueOf(value interface{}, typ reflect.Type) (reflect.Value, error) {
+	v := reflect.ValueOf(value)
+	if v.Type().AssignableTo(typ) {
+		return v, nil
+	}
+
+	if typ.Kind() == reflect.Ptr && v.Type().AssignableTo(typ.Elem()) {
+		ptr := reflect.New(typ.Elem())
+		ptr.Elem().Set(v)
+		return ptr, nil
+	}
+
+	return reflect.Value{}, fmt.Errorf("Cannot convert %v to %s, got: %T", value, typ, value)
+}
+
+// mapKeyOfChangePath returns key of map, which is given as item of path of change
+func mapKeyOfChangePath(node reflect.Value, pathItem string) (reflect.Value, error) {
+	for _, key := range node.MapKeys() {
+		if fmt.Sprint(key.Interface()) == pathItem {
+			return key, nil
+		}
+	}
+
+	keyType := node.Type().Key()
+	switch keyType.Kind() {
+	case reflect.String:
+		return reflect.ValueOf(pathItem).Convert(keyType), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		key, err := strconv.ParseUint(pathItem, 10, keyType.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(key).Convert(keyType), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		key, err := strconv.ParseInt(pathItem, 10, keyType.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(key).Convert(keyType), nil
+	default:
+		return reflect.Value{}, fmt.Errorf("Unsupported key %s of map %s", pathItem, node.Type())
+	}
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/openconfig/ygot/ygot"
+	"github.com/r3labs/diff"
+
+	"opennos-mgmt/gnmi/modeldata/oc"
+)
+
+func newTestDevice(descriptions map[string]string) *oc.Device {
+	device := &oc.Device{}
+	for ifname, description := range descriptions {
+		intf := device.GetOrCreateInterface(ifname)
+		if description != "" {
+			intf.Description = ygot.String(description)
+		}
+	}
+	return device
+}
+
+func TestApplyChangelog(t *testing.T) {
+	scheduledFrom := newTestDevice(map[string]string{"eth-1": "uplink", "eth-2": ""})
+	scheduledTo := newTestDevice(map[string]string{"eth-1": "core", "eth-2": "", "eth-3": "spare"})
+	changelog, err := diff.Diff(scheduledFrom, scheduledTo)
+	if err != nil {
+		t.Fatalf("error in getting diff of config: %v", err)
+	}
+
+	tests := []struct {
+		desc      string
+		running   *oc.Device
+		want      *oc.Device
+		wantError bool
+	}{{
+		desc:    "running config has not changed",
+		running: newTestDevice(map[string]string{"eth-1": "uplink", "eth-2": ""}),
+		want:    newTestDevice(map[string]string{"eth-1": "core", "eth-2": "", "eth-3": "spare"}),
+	}, {
+		desc:    "unrelated node has changed",
+		running: newTestDevice(map[string]string{"eth-1": "uplink", "eth-2": "downlink"}),
+		want:    newTestDevice(map[string]string{"eth-1": "core", "eth-2": "downlink", "eth-3": "spare"}),
+	}, {
+		desc:    "scheduled change has been already applied",
+		running: newTestDevice(map[string]string{"eth-1": "core", "eth-2": ""}),
+		want:    newTestDevice(map[string]string{"eth-1": "core", "eth-2": "", "eth-3": "spare"}),
+	}, {
+		desc:      "scheduled node has changed",
+		running:   newTestDevice(map[string]string{"eth-1": "backbone", "eth-2": ""}),
+		wantError: true,
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := applyChangelog(changelog, tc.running)
+			if tc.wantError {
+				if err == nil {
+					t.Errorf("applyChangelog() succeeded, want error of conflict")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyChangelog() = %v, want nil", err)
+			}
+			gotChangelog, err := diff.Diff(tc.running, tc.want)
+			if err != nil {
+				t.Fatalf("error in getting diff of config: %v", err)
+			}
+			if len(gotChangelog) != 0 {
+				t.Errorf("got config different from wanted by %+v", gotChangelog)
+			}
+		})
+	}
+}
@@ -28,6 +28,8 @@ type CommandI interface {
 	// false, nil - if 'other' has not been appended, because particular command does not support this capability;
 	// false, error - if there was an error during appending 'other'
 	Append(other CommandI) (bool, error)
+	// GetChanges returns changes of config, which are applied by command
+	GetChanges() []*diff.Change
 }
 
 // commandT is desired to embed in derivation type of Command pattern interface for use common
@@ -128,6 +130,16 @@ func (this *commandT) append(cmd CommandI) (bool, error) {
 	return true, nil
 }
 
+// GetChanges returns changes of config, which are applied by command. Command without
+// internal data, e.g. Nil Object, has no changes.
+func (this *commandT) GetChanges() []*diff.Change {
+	if this == nil {
+		return nil
+	}
+
+	return this.changes
+}
+
 func (this *commandT) erase() {
 	this.ethSwitchMgmt = nil
 	this.name = ""
@@ -147,6 +159,10 @@ func getCommandT(cmd CommandI) (*commandT, error) {
 		cmdT = SetAggIntfMemberCmdT(*v).commandT
 	case *DeleteAggIntfMemberCmdT:
 		cmdT = DeleteAggIntfMemberCmdT(*v).commandT
+	case *SetLacpCmdT:
+		cmdT = SetLacpCmdT(*v).commandT
+	case *DeleteLacpCmdT:
+		cmdT = DeleteLacpCmdT(*v).commandT
 	case *SetIpv4AddrEthIntfCmdT:
 		cmdT = SetIpv4AddrEthIntfCmdT(*v).commandT
 	case *DeleteIpv4AddrEthIntfCmdT:
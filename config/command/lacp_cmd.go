@@ -1,20 +1,29 @@
 package command
 
 import (
+	"context"
 	"fmt"
 	mgmt "opennos-eth-switch-service/mgmt"
+	"opennos-eth-switch-service/mgmt/interfaces"
+	"opennos-mgmt/utils"
+	"time"
 
 	"github.com/r3labs/diff"
 )
 
 const (
-	LacpInterfacePathItemIdxC = 0
-	LacpIfnamePathItemIdxC    = 1
-	LacpNamePathItemIdxC      = 2
-	LacpPathItemsCountC       = 3
+	// Common for all subtrees changes of LACP interface
+	LacpPathItemIdxC          = 0
+	LacpInterfacePathItemIdxC = 1
+	LacpIfnamePathItemIdxC    = 2
+	LacpPathItemsCountC       = 4
 
+	LacpPathItemC          = "Lacp"
 	LacpInterfacePathItemC = "Interface"
-	LacpNamePathItemC      = "Name"
+
+	// LACP interface change
+	LacpNamePathItemIdxC = 3
+	LacpNamePathItemC    = "Name"
 )
 
 const (
@@ -22,12 +31,7 @@ const (
 	maxLacpChangeIdxC
 )
 
-const (
-	lacpMemberChangeIdxC = iota
-	maxLacpMemberChangeIdxC
-)
-
-// SetLacpCmdT implements command for creating LACP
+// SetLacpCmdT implements command for enabling LACP on aggregate interface
 type SetLacpCmdT struct {
 	*commandT // commandT is embedded as a pointer because its state will be modify
 }
@@ -41,22 +45,22 @@ func NewSetLacpCmdT(change *diff.Change, ethSwitchMgmt *mgmt.EthSwitchMgmtClient
 	}
 }
 
-// Execute implements the same mlacpod from CommandI interface and creates LACP
+// Execute implements the same method from CommandI interface and enables LACP
 func (this *SetLacpCmdT) Execute() error {
 	shouldBeAbleOnlyToUndo := false
 	isGoingToBeDeleted := false
 	return doLacpCmd(this.commandT, isGoingToBeDeleted, shouldBeAbleOnlyToUndo)
 }
 
-// Undo implements the same mlacpod from CommandI interface and withdraws changes performed by
-// previously execution of Execute() mlacpod
+// Undo implements the same method from CommandI interface and withdraws changes performed by
+// previously execution of Execute() method
 func (this *SetLacpCmdT) Undo() error {
 	shouldBeAbleOnlyToUndo := true
 	isGoingToBeDeleted := true
 	return doLacpCmd(this.commandT, isGoingToBeDeleted, shouldBeAbleOnlyToUndo)
 }
 
-// GetName implements the same mlacpod from CommandI interface and returns name of command
+// GetName implements the same method from CommandI interface and returns name of command
 func (this *SetLacpCmdT) GetName() string {
 	return this.name
 }
@@ -72,7 +76,7 @@ func (this *SetLacpCmdT) Append(cmd CommandI) (bool, error) {
 	return false, fmt.Errorf("Unsupported")
 }
 
-// SetLacpCmdT implements command for removing LACP
+// DeleteLacpCmdT implements command for disabling LACP on aggregate interface
 type DeleteLacpCmdT struct {
 	*commandT // commandT is embedded as a pointer because its state will be modify
 }
@@ -86,22 +90,22 @@ func NewDeleteLacpCmdT(change *diff.Change, ethSwitchMgmt *mgmt.EthSwitchMgmtCli
 	}
 }
 
-// Execute implements the same mlacpod from CommandI interface and creates LACP
+// Execute implements the same method from CommandI interface and disables LACP
 func (this *DeleteLacpCmdT) Execute() error {
 	shouldBeAbleOnlyToUndo := false
-	isGoingToBeDeleted := false
+	isGoingToBeDeleted := true
 	return doLacpCmd(this.commandT, isGoingToBeDeleted, shouldBeAbleOnlyToUndo)
 }
 
-// Undo implements the same mlacpod from CommandI interface and withdraws changes performed by
-// previously execution of Execute() mlacpod
+// Undo implements the same method from CommandI interface and withdraws changes performed by
+// previously execution of Execute() method
 func (this *DeleteLacpCmdT) Undo() error {
 	shouldBeAbleOnlyToUndo := true
-	isGoingToBeDeleted := true
+	isGoingToBeDeleted := false
 	return doLacpCmd(this.commandT, isGoingToBeDeleted, shouldBeAbleOnlyToUndo)
 }
 
-// GetName implements the same mlacpod from CommandI interface and returns name of command
+// GetName implements the same method from CommandI interface and returns name of command
 func (this *DeleteLacpCmdT) GetName() string {
 	return this.name
 }
@@ -117,96 +121,6 @@ func (this *DeleteLacpCmdT) Append(cmd CommandI) (bool, error) {
 	return false, fmt.Errorf("Unsupported")
 }
 
-// SetLacpMemberCmdT implements command for add members to LACP
-type SetLacpMemberCmdT struct {
-	*commandT // commandT is embedded as a pointer because its state will be modify
-}
-
-// NewSetLacpCmdT creates new instance of SetLacpMemberCmdT type
-func NewSetLacpMemberCmdT(change *diff.Change, ethSwitchMgmt *mgmt.EthSwitchMgmtClient) *SetLacpMemberCmdT {
-	changes := make([]*diff.Change, maxLacpMemberChangeIdxC)
-	changes[lacpMemberChangeIdxC] = change
-	return &SetLacpMemberCmdT{
-		commandT: newCommandT("set lacp member", changes, ethSwitchMgmt),
-	}
-}
-
-// Execute implements the same mlacpod from CommandI interface and creates LACP
-func (this *SetLacpMemberCmdT) Execute() error {
-	shouldBeAbleOnlyToUndo := false
-	isGoingToBeDeleted := false
-	return doLacpMemberCmd(this.commandT, isGoingToBeDeleted, shouldBeAbleOnlyToUndo)
-}
-
-// Undo implements the same mlacpod from CommandI interface and withdraws changes performed by
-// previously execution of Execute() mlacpod
-func (this *SetLacpMemberCmdT) Undo() error {
-	shouldBeAbleOnlyToUndo := true
-	isGoingToBeDeleted := true
-	return doLacpMemberCmd(this.commandT, isGoingToBeDeleted, shouldBeAbleOnlyToUndo)
-}
-
-// GetName implements the same mlacpod from CommandI interface and returns name of command
-func (this *SetLacpMemberCmdT) GetName() string {
-	return this.name
-}
-
-// Equals checks if 'this' command and 'other' command are the same... do the same thing
-func (this *SetLacpMemberCmdT) Equals(other CommandI) bool {
-	otherCmd := other.(*SetLacpMemberCmdT)
-	return this.equals(otherCmd.commandT)
-}
-
-// Append is not supported
-func (this *SetLacpMemberCmdT) Append(cmd CommandI) (bool, error) {
-	return false, fmt.Errorf("Unsupported")
-}
-
-// SetLacpMemberCmdT implements command for removing LACP
-type DeleteLacpMemberCmdT struct {
-	*commandT // commandT is embedded as a pointer because its state will be modify
-}
-
-// NewDeleteLacpMemberCmdT creates new instance of DeleteLacpCmdT type
-func NewDeleteLacpMemberCmdT(change *diff.Change, ethSwitchMgmt *mgmt.EthSwitchMgmtClient) *DeleteLacpMemberCmdT {
-	changes := make([]*diff.Change, maxLacpMemberChangeIdxC)
-	changes[lacpMemberChangeIdxC] = change
-	return &DeleteLacpMemberCmdT{
-		commandT: newCommandT("delete lacp member", changes, ethSwitchMgmt),
-	}
-}
-
-// Execute implements the same mlacpod from CommandI interface and creates LACP
-func (this *DeleteLacpMemberCmdT) Execute() error {
-	shouldBeAbleOnlyToUndo := false
-	isGoingToBeDeleted := false
-	return doLacpCmd(this.commandT, isGoingToBeDeleted, shouldBeAbleOnlyToUndo)
-}
-
-// Undo implements the same mlacpod from CommandI interface and withdraws changes performed by
-// previously execution of Execute() mlacpod
-func (this *DeleteLacpMemberCmdT) Undo() error {
-	shouldBeAbleOnlyToUndo := true
-	isGoingToBeDeleted := true
-	return doLacpCmd(this.commandT, isGoingToBeDeleted, shouldBeAbleOnlyToUndo)
-}
-
-// GetName implements the same mlacpod from CommandI interface and returns name of command
-func (this *DeleteLacpMemberCmdT) GetName() string {
-	return this.name
-}
-
-// Equals checks if 'this' command and 'other' command are the same... do the same thing
-func (this *DeleteLacpMemberCmdT) Equals(other CommandI) bool {
-	otherCmd := other.(*DeleteLacpMemberCmdT)
-	return this.equals(otherCmd.commandT)
-}
-
-// Append is not supported
-func (this *DeleteLacpMemberCmdT) Append(cmd CommandI) (bool, error) {
-	return false, fmt.Errorf("Unsupported")
-}
-
 func doLacpCmd(cmd *commandT, isDelete bool, shouldBeAbleOnlyToUndo bool) error {
 	if cmd.isAbleOnlyToUndo() != shouldBeAbleOnlyToUndo {
 		return cmd.createErrorAccordingToExecutionState()
@@ -214,77 +128,36 @@ func doLacpCmd(cmd *commandT, isDelete bool, shouldBeAbleOnlyToUndo bool) error
 
 	cmd.dumpInternalData()
 
-	// var err error
-	// var ifname string
-	// if isDelete {
-	// 	ifname, err = utils.ConvertGoInterfaceIntoString(cmd.changes[0].From)
-	// } else {
-	// 	ifname, err = utils.ConvertGoInterfaceIntoString(cmd.changes[0].To)
-	// }
-	// if err != nil {
-	// 	return err
-	// }
-
-	// ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-	// defer cancel()
-	// if isDelete {
-	// 	_, err = (*cmd.ethSwitchMgmt).DeleteLacp(ctx, &interfaces.DeleteLacpRequest{
-	// 		Lacp: &interfaces.Lacp{
-	// 			Ifname: ifname,
-	// 		},
-	// 	})
-	// } else {
-	// 	_, err = (*cmd.ethSwitchMgmt).CreateLacp(ctx, &interfaces.CreateLacpRequest{
-	// 		Lacp: &interfaces.Lacp{
-	// 			Ifname: ifname,
-	// 		},
-	// 	})
-	// }
-	// if err != nil {
-	// 	return err
-	// }
-
-	// cmd.finalize()
-	return nil
-}
-
-func doLacpMemberCmd(cmd *commandT, isDelete bool, shouldBeAbleOnlyToUndo bool) error {
-	if cmd.isAbleOnlyToUndo() != shouldBeAbleOnlyToUndo {
-		return cmd.createErrorAccordingToExecutionState()
+	var err error
+	var ifname string
+	if isDelete {
+		ifname, err = utils.ConvertGoInterfaceIntoString(cmd.changes[lacpChangeIdxC].From)
+	} else {
+		ifname, err = utils.ConvertGoInterfaceIntoString(cmd.changes[lacpChangeIdxC].To)
+	}
+	if err != nil {
+		return err
 	}
 
-	cmd.dumpInternalData()
-
-	// var err error
-	// var ifname string
-	// if isDelete {
-	// 	ifname, err = utils.ConvertGoInterfaceIntoString(cmd.changes[0].From)
-	// } else {
-	// 	ifname, err = utils.ConvertGoInterfaceIntoString(cmd.changes[0].To)
-	// }
-	// if err != nil {
-	// 	return err
-	// }
-
-	// ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-	// defer cancel()
-	// if isDelete {
-	// 	_, err = (*cmd.ethSwitchMgmt).DeleteLacp(ctx, &interfaces.DeleteLacpRequest{
-	// 		Lacp: &interfaces.Lacp{
-	// 			Ifname: ifname,
-	// 		},
-	// 	})
-	// } else {
-	// 	_, err = (*cmd.ethSwitchMgmt).CreateLacp(ctx, &interfaces.CreateLacpRequest{
-	// 		Lacp: &interfaces.Lacp{
-	// 			Ifname: ifname,
-	// 		},
-	// 	})
-	// }
-	// if err != nil {
-	// 	return err
-	// }
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if isDelete {
+		_, err = (*cmd.ethSwitchMgmt).DeleteLacp(ctx, &interfaces.DeleteLacpRequest{
+			Lacp: &interfaces.Lacp{
+				Ifname: ifname,
+			},
+		})
+	} else {
+		_, err = (*cmd.ethSwitchMgmt).CreateLacp(ctx, &interfaces.CreateLacpRequest{
+			Lacp: &interfaces.Lacp{
+				Ifname: ifname,
+			},
+		})
+	}
+	if err != nil {
+		return err
+	}
 
-	// cmd.finalize()
+	cmd.finalize()
 	return nil
 }
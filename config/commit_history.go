@@ -0,0 +1,137 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"opennos-mgmt/gnmi/modeldata/oc"
+	"time"
+
+	"github.com/openconfig/ygot/ygot"
+	"github.com/r3labs/diff"
+)
+
+const (
+	maxCommitHistorySizeC = 32 // The number of the last commits, which can be restored
+
+	mgmtCommitPathItemIdxC = 1
+	mgmtCommitPathItemC    = "Commit"
+)
+
+// commitRecordT describes config committed into running config
+type commitRecordT struct {
+	id        uint32
+	timestamp time.Time
+	user      string
+	comment   string
+	label     string
+	changelog diff.Changelog
+	config    ygot.ValidatedGoStruct // Committed config without commit history and scheduled commits
+}
+
+// commitHistoryT is ring of the last committed configs
+type commitHistoryT struct {
+	records    []*commitRecordT
+	lastCommit uint32
+}
+
+func newCommitHistory() *commitHistoryT {
+	return &commitHistoryT{
+		records: make([]*commitRecordT, 0, maxCommitHistorySizeC),
+	}
+}
+
+// add records config committed by user. Config is copied, so caller can still modify it.
+func (this *commitHistoryT) add(user string, comment string, label string, changelog diff.Changelog, config ygot.ValidatedGoStruct) (*commitRecordT, error) {
+	configCopy, err := ygot.DeepCopy(config)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to copy committed config: %s", err)
+	}
+
+	device := configCopy.(*oc.Device)
+	if device.Management != nil {
+		device.Management.Commit = nil
+		device.Management.ScheduledCommit = nil
+	}
+
+	this.lastCommit++
+	record := &commitRecordT{
+		id:        this.lastCommit,
+		timestamp: time.Now(),
+		user:      user,
+		comment:   comment,
+		label:     label,
+		changelog: changelog,
+		config:    device,
+	}
+
+	if len(this.records) == maxCommitHistorySizeC {
+		this.records = this.records[1:]
+	}
+	this.records = append(this.records, record)
+	return record, nil
+}
+
+// find returns commit of given ID or nil if it is not kept in history anymore
+func (this *commitHistoryT) find(id uint32) *commitRecordT {
+	for _, record := range this.records {
+		if record.id == id {
+			return record
+		}
+	}
+
+	return nil
+}
+
+// toOc converts history into list of commits of management model
+func (this *commitHistoryT) toOc() (map[uint32]*oc.Management_Commit, error) {
+	commits := make(map[uint32]*oc.Management_Commit, len(this.records))
+	for _, record := range this.records {
+		jsonDump, err := json.Marshal(record.changelog)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to JSON dump changelog of commit %d: %s", record.id, err)
+		}
+
+		id := record.id
+		timestamp := uint64(record.timestamp.UnixNano())
+		user := record.user
+		changelog := string(jsonDump)
+		commit := &oc.Management_Commit{
+			Id:        &id,
+			Timestamp: &timestamp,
+			User:      &user,
+			Changelog: &changelog,
+		}
+		if record.comment != "" {
+			comment := record.comment
+			commit.Comment = &comment
+		}
+		if record.label != "" {
+			label := record.label
+			commit.Label = &label
+		}
+		commits[id] = commit
+	}
+
+	return commits, nil
+}
+
+func isCommitHistoryChange(change *diff.Change) bool {
+	if len(change.Path) <= mgmtCommitPathItemIdxC {
+		return false
+	}
+
+	return (change.Path[mgmtTransManagementPathItemIdxC] == mgmtTransManagementPathItemC) && (change.Path[mgmtCommitPathItemIdxC] == mgmtCommitPathItemC)
+}
+
+// filterMgmtStateChanges removes changes of commit history and scheduled commits, which are maintained
+// by device itself
+func filterMgmtStateChanges(changelog diff.Changelog) diff.Changelog {
+	changes := make(diff.Changelog, 0, len(changelog))
+	for i := 0; i < len(changelog); i++ {
+		if !isCommitHistoryChange(&changelog[i]) && !isScheduledCommitsChange(&changelog[i]) {
+			changes = append(changes, changelog[i])
+		}
+	}
+
+	return changes
+}
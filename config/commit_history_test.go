@@ -0,0 +1,75 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/openconfig/ygot/ygot"
+
+	"opennos-mgmt/gnmi"
+	"opennos-mgmt/gnmi/modeldata"
+	"opennos-mgmt/gnmi/modeldata/oc"
+)
+
+func TestCommitsWithTheSameLabel(t *testing.T) {
+	dir, err := ioutil.TempDir("", "opennos-mgmt")
+	if err != nil {
+		t.Fatalf("error in creating temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("error in getting working directory: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("error in changing working directory: %v", err)
+	}
+	defer os.Chdir(wd)
+
+	model := gnmi.NewModel(modeldata.ModelData,
+		reflect.TypeOf((*oc.Device)(nil)),
+		oc.SchemaTree["Device"],
+		oc.Unmarshal,
+		oc.ΛEnum)
+	startupConfig, err := model.NewConfigStruct([]byte(`{"interfaces": {"interface": [{"name": "eth-1", "config": {"name": "eth-1", "description": "uplink"}}]}}`))
+	if err != nil {
+		t.Fatalf("error in creating config struct: %v", err)
+	}
+
+	configMngr := NewConfigMngrT()
+	err = configMngr.doInCommitWorker(func() error {
+		configMngr.runningConfig = startupConfig
+		for _, description := range []string{"downlink", "spine"} {
+			configCopy, err := ygot.DeepCopy(configMngr.runningConfig)
+			if err != nil {
+				return err
+			}
+			device := configCopy.(*oc.Device)
+			device.GetInterface("eth-1").Description = ygot.String(description)
+			device.GetOrCreateManagement().GetOrCreateTransaction().Label = ygot.String("CHG-1")
+			candidateConfig := ygot.ValidatedGoStruct(device)
+			if err := configMngr.commitCandidateConfig(&candidateConfig); err != nil {
+				return err
+			}
+			if label := device.GetManagement().GetTransaction().GetLabel(); label != "" {
+				t.Errorf("label of candidate config after commit = %q, want it cleared", label)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("error in committing config: %v", err)
+	}
+
+	for _, id := range []uint32{1, 2} {
+		record := configMngr.commitHistory.find(id)
+		if record == nil {
+			t.Fatalf("commit %d not found in history", id)
+		}
+		if record.label != "CHG-1" {
+			t.Errorf("label of commit %d = %q, want %q", id, record.label, "CHG-1")
+		}
+	}
+}
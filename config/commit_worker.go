@@ -0,0 +1,205 @@
+package config
+
+import (
+	"opennos-mgmt/gnmi"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/openconfig/ygot/ygot"
+	"github.com/r3labs/diff"
+)
+
+const (
+	commitQueueSizeC = 16
+)
+
+// commitRequestT is request handled by commit worker. Every access to state of config manager
+// goes through commit worker, so clients, confirmation timeout and rollback never interleave.
+type commitRequestT struct {
+	handle func() error
+	result chan error
+}
+
+// notificationQueueT delivers notifications one by one in order they have been queued, without
+// blocking the one, who queues them
+type notificationQueueT struct {
+	mtx      sync.Mutex
+	pending  []func()
+	isActive bool // marks if notifications are being delivered
+}
+
+func (this *notificationQueueT) push(notify func()) {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	this.pending = append(this.pending, notify)
+	if !this.isActive {
+		this.isActive = true
+		go this.deliver()
+	}
+}
+
+func (this *notificationQueueT) deliver() {
+	for {
+		this.mtx.Lock()
+		if len(this.pending) == 0 {
+			this.isActive = false
+			this.mtx.Unlock()
+			return
+		}
+
+		notify := this.pending[0]
+		this.pending = this.pending[1:]
+		this.mtx.Unlock()
+		notify()
+	}
+}
+
+func (this *ConfigMngrT) runCommitWorker() {
+	for request := range this.commitQueue {
+		request.result <- request.handle()
+	}
+}
+
+// doInCommitWorker queues request and waits until it is handled. It must not be called by
+// handler of other request, because commit worker would wait for itself.
+func (this *ConfigMngrT) doInCommitWorker(handle func() error) error {
+	request := &commitRequestT{
+		handle: handle,
+		result: make(chan error, 1),
+	}
+
+	this.commitQueue <- request
+	return <-request.result
+}
+
+// doClientChangeInCommitWorker handles request of client, which reports if the client replaces its
+// config on success. Running configs notified before are superseded by such change.
+func (this *ConfigMngrT) doClientChangeInCommitWorker(handle func() (bool, error)) error {
+	return this.doInCommitWorker(func() error {
+		this.isHandlingClientChange = true
+		defer func() { this.isHandlingClientChange = false }()
+		isChanged, err := handle()
+		if err != nil {
+			return err
+		}
+
+		if isChanged {
+			atomic.AddUint64(&this.clientConfigGeneration, 1)
+		}
+		return nil
+	})
+}
+
+// SetChangelogNotifier sets function notified about changes of running config
+func (this *ConfigMngrT) SetChangelogNotifier(notifier ChangelogNotifierT) {
+	this.doInCommitWorker(func() error {
+		this.changelogNotifier = notifier
+		return nil
+	})
+}
+
+// SetRunningConfigNotifier sets function notified about running config replaced by rollback or scheduled commit
+func (this *ConfigMngrT) SetRunningConfigNotifier(notifier RunningConfigNotifierT) {
+	this.doInCommitWorker(func() error {
+		this.runningConfigNotifier = notifier
+		return nil
+	})
+}
+
+// SetTransSession sets client session, which requests the next changes of config
+func (this *ConfigMngrT) SetTransSession(session SessionT) {
+	this.doInCommitWorker(func() error {
+		this.transSession = session
+		return nil
+	})
+}
+
+// LoadConfig configures device with startup config
+func (this *ConfigMngrT) LoadConfig(model *gnmi.Model, config []byte) error {
+	return this.doInCommitWorker(func() error {
+		return this.loadConfig(model, config)
+	})
+}
+
+// GetResumedCommit returns ID of commit awaiting confirmation since before restart, config
+// awaiting confirmation and config to be restored if commit is not confirmed
+func (this *ConfigMngrT) GetResumedCommit() (string, ygot.ValidatedGoStruct, ygot.ValidatedGoStruct, bool) {
+	var id string
+	var commitConfig, rollbackConfig ygot.ValidatedGoStruct
+	var resumed bool
+	this.doInCommitWorker(func() error {
+		id, commitConfig, rollbackConfig, resumed = this.getResumedCommit()
+		return nil
+	})
+
+	return id, commitConfig, rollbackConfig, resumed
+}
+
+// GetRunningConfig returns IETF JSON of running config, e.g. to start serving config, which is
+// already applied on device instead of startup config
+func (this *ConfigMngrT) GetRunningConfig() ([]byte, error) {
+	var jsonDump []byte
+	err := this.doInCommitWorker(func() error {
+		var err error
+		jsonDump, err = gnmi.ConvertYgotGoStructIntoJsonByteStream(this.runningConfig)
+		return err
+	})
+
+	return jsonDump, err
+}
+
+// GetDiffRunningConfigWithCandidateConfig returns changes of config, except history of commits maintained by device
+func (this *ConfigMngrT) GetDiffRunningConfigWithCandidateConfig(candidateConfig *ygot.ValidatedGoStruct) (diff.Changelog, error) {
+	var changelog diff.Changelog
+	err := this.doInCommitWorker(func() error {
+		var err error
+		changelog, err = this.diffRunningConfigWithCandidateConfig(candidateConfig)
+		return err
+	})
+
+	return changelog, err
+}
+
+// CommitChangelog applies changes according to config action of management transaction. Result is
+// returned, if changes have not been simply applied, e.g. they have been validated only.
+func (this *ConfigMngrT) CommitChangelog(changelog *diff.Changelog, candidateConfig *ygot.ValidatedGoStruct) (*gnmi.ApplyResult, error) {
+	var result *gnmi.ApplyResult
+	err := this.doClientChangeInCommitWorker(func() (bool, error) {
+		var err error
+		result, err = this.commitChangelog(changelog, candidateConfig)
+		// Dry run leaves config of client intact
+		return (result == nil) || (result.DryRun == nil), err
+	})
+
+	return result, err
+}
+
+// CommitChangelogConfirmed commits changes, which are rolled back if commit of given ID is not confirmed in time.
+// Zero rollback duration means the commit confirmation timeout of management transaction.
+func (this *ConfigMngrT) CommitChangelogConfirmed(changelog *diff.Changelog, candidateConfig *ygot.ValidatedGoStruct, commitID string, rollbackDuration time.Duration) error {
+	return this.doClientChangeInCommitWorker(func() (bool, error) {
+		return true, this.commitChangelogConfirmed(changelog, candidateConfig, commitID, rollbackDuration)
+	})
+}
+
+// ConfirmCommit applies permanently changes of pending commit of given ID
+func (this *ConfigMngrT) ConfirmCommit(commitID string) error {
+	return this.doClientChangeInCommitWorker(func() (bool, error) {
+		return true, this.confirmCommit(commitID)
+	})
+}
+
+// CancelCommit withdraws changes of pending commit of given ID
+func (this *ConfigMngrT) CancelCommit(commitID string) error {
+	return this.doClientChangeInCommitWorker(func() (bool, error) {
+		return true, this.cancelCommit(commitID)
+	})
+}
+
+// SetCommitRollbackDuration restarts counting for confirmation of pending commit of given ID
+func (this *ConfigMngrT) SetCommitRollbackDuration(commitID string, rollbackDuration time.Duration) error {
+	return this.doInCommitWorker(func() error {
+		return this.setCommitRollbackDuration(commitID, rollbackDuration)
+	})
+}
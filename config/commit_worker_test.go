@@ -0,0 +1,68 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/openconfig/ygot/ygot"
+
+	"opennos-mgmt/gnmi/modeldata/oc"
+)
+
+func TestNotificationQueueDeliversInOrder(t *testing.T) {
+	var queue notificationQueueT
+	delivered := make(chan int)
+	const count = 100
+	for i := 0; i < count; i++ {
+		i := i
+		queue.push(func() {
+			delivered <- i
+		})
+	}
+
+	var got, want []int
+	for i := 0; i < count; i++ {
+		got = append(got, <-delivered)
+		want = append(want, i)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got notifications in order %v, want %v", got, want)
+	}
+}
+
+func TestRunningConfigSupersededByClientChange(t *testing.T) {
+	configMngr := NewConfigMngrT()
+	notified := make(chan func() bool, 2)
+	configMngr.SetRunningConfigNotifier(func(runningConfig ygot.ValidatedGoStruct, isCurrent func() bool) {
+		notified <- isCurrent
+	})
+	configMngr.doInCommitWorker(func() error {
+		configMngr.runningConfig = &oc.Device{}
+		return nil
+	})
+
+	// Rollback requested by client is notified along with the change, which requests it
+	configMngr.doClientChangeInCommitWorker(func() (bool, error) {
+		configMngr.notifyRunningConfig()
+		return true, nil
+	})
+	if isCurrent := <-notified; !isCurrent() {
+		t.Errorf("running config notified by change of client is superseded by the change itself")
+	}
+
+	// Rollback due to timeout is superseded by the next change of client
+	configMngr.doInCommitWorker(func() error {
+		configMngr.notifyRunningConfig()
+		return nil
+	})
+	isCurrent := <-notified
+	if !isCurrent() {
+		t.Errorf("running config is superseded before any change of client")
+	}
+	configMngr.doClientChangeInCommitWorker(func() (bool, error) {
+		return true, nil
+	})
+	if isCurrent() {
+		t.Errorf("running config is not superseded by change of client applied after it")
+	}
+}
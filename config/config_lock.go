@@ -0,0 +1,143 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"opennos-mgmt/gnmi/modeldata/oc"
+	"time"
+
+	log "github.com/golang/glog"
+	"github.com/openconfig/ygot/ygot"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	configLockIdleTimeoutC = 10 * time.Minute // Lock expires if session holding it does not change config
+)
+
+// SessionT identifies client session requesting changes of config
+type SessionT struct {
+	User    string
+	Peer    string // Address of gRPC peer
+	IsAdmin bool   // Administrator is allowed to break lock held by other session
+}
+
+func (this SessionT) String() string {
+	return fmt.Sprintf("%q@%s", this.User, this.Peer)
+}
+
+// isSameUserAs checks if sessions belong to the same user. Lock of config is held by user, because
+// peer address changes with every connection of client.
+func (this SessionT) isSameUserAs(other SessionT) bool {
+	return this.User == other.User
+}
+
+// configLockT is exclusive lock of config held by user of client session
+type configLockT struct {
+	session      SessionT // Session, which has locked config
+	lastActivity time.Time
+}
+
+// lockConfig locks config for exclusive use of user requesting changes
+func (this *ConfigMngrT) lockConfig() error {
+	if err := this.checkConfigLock(); err != nil {
+		return err
+	}
+
+	if this.configLock != nil {
+		log.Infof("Configuration is already locked by session %s", this.configLock.session)
+		return nil
+	}
+
+	this.configLock = &configLockT{
+		session:      this.transSession,
+		lastActivity: time.Now(),
+	}
+	log.Infof("Configuration has been locked by session %s", this.transSession)
+	return nil
+}
+
+// unlockConfig releases lock of config held by user requesting changes
+func (this *ConfigMngrT) unlockConfig() error {
+	this.expireConfigLock()
+	if this.configLock == nil {
+		return newValidationErr(ValidationReasonUnavailableC, errors.New("Configuration is not locked"))
+	}
+
+	if !this.configLock.session.isSameUserAs(this.transSession) {
+		return status.Errorf(codes.FailedPrecondition, "Configuration is locked by other user %q", this.configLock.session.User)
+	}
+
+	this.configLock = nil
+	log.Infof("Configuration has been unlocked by session %s", this.transSession)
+	return nil
+}
+
+// breakConfigLock releases lock of config held by any user. Only administrator is allowed to do it.
+func (this *ConfigMngrT) breakConfigLock() error {
+	if !this.transSession.IsAdmin {
+		return status.Errorf(codes.PermissionDenied, "User %q is not allowed to break lock of configuration", this.transSession.User)
+	}
+
+	this.expireConfigLock()
+	if this.configLock == nil {
+		return newValidationErr(ValidationReasonUnavailableC, errors.New("Configuration is not locked"))
+	}
+
+	log.Infof("Lock of configuration held by session %s has been broken by session %s", this.configLock.session, this.transSession)
+	this.configLock = nil
+	return nil
+}
+
+// checkConfigLock verifies if user requesting changes is allowed to change config. Every change
+// requested by user holding the lock keeps the lock alive.
+func (this *ConfigMngrT) checkConfigLock() error {
+	this.expireConfigLock()
+	if this.configLock == nil {
+		return nil
+	}
+
+	if !this.configLock.session.isSameUserAs(this.transSession) {
+		return status.Errorf(codes.FailedPrecondition, "Configuration is locked by user %q", this.configLock.session.User)
+	}
+
+	this.configLock.lastActivity = time.Now()
+	return nil
+}
+
+func (this *ConfigMngrT) expireConfigLock() {
+	if this.configLock == nil {
+		return
+	}
+
+	if time.Since(this.configLock.lastActivity) > configLockIdleTimeoutC {
+		log.Infof("Lock of configuration held by session %s has expired", this.configLock.session)
+		this.configLock = nil
+	}
+}
+
+func isConfigLockAction(configAction oc.E_OpenconfigManagement_TRANS_TYPE) bool {
+	switch configAction {
+	case oc.OpenconfigManagement_TRANS_TYPE_TRANS_LOCK, oc.OpenconfigManagement_TRANS_TYPE_TRANS_UNLOCK, oc.OpenconfigManagement_TRANS_TYPE_TRANS_BREAK_LOCK:
+		return true
+	}
+
+	return false
+}
+
+// doConfigLockAction applies lock action requested by session. Config action is not kept in
+// candidate config, so the same action can be requested again.
+func (this *ConfigMngrT) doConfigLockAction(configAction oc.E_OpenconfigManagement_TRANS_TYPE, candidateConfig *ygot.ValidatedGoStruct) error {
+	(*candidateConfig).(*oc.Device).GetOrCreateManagement().GetOrCreateTransaction().ConfigAction = oc.OpenconfigManagement_TRANS_TYPE_UNSET
+	switch configAction {
+	case oc.OpenconfigManagement_TRANS_TYPE_TRANS_LOCK:
+		return this.lockConfig()
+	case oc.OpenconfigManagement_TRANS_TYPE_TRANS_UNLOCK:
+		return this.unlockConfig()
+	case oc.OpenconfigManagement_TRANS_TYPE_TRANS_BREAK_LOCK:
+		return this.breakConfigLock()
+	default:
+		return fmt.Errorf("Config action %s is not lock action", configAction)
+	}
+}
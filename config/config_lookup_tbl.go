@@ -125,7 +125,7 @@ func (this *configLookupTablesT) setEthIntf(ethIfname string) error {
 func (this *configLookupTablesT) deleteEthIntf(ethIfname string) error {
 	ethIdx, exists := this.idxByEthIfname[ethIfname]
 	if !exists {
-		return fmt.Errorf("Ethernet interface %s does not exist", ethIfname)
+		return newValidationErr(ValidationReasonUnavailableC, fmt.Errorf("Ethernet interface %s does not exist", ethIfname))
 	}
 
 	delete(this.idxByEthIfname, ethIfname)
@@ -217,7 +217,7 @@ func (this *configLookupTablesT) checkDependenciesForDeleteEthIntf(ifname string
 func (this *configLookupTablesT) checkDependenciesForSetAggIntfMember(aggIfname string, ifname string) error {
 	intfIdx, exists := this.idxByEthIfname[ifname]
 	if !exists {
-		return fmt.Errorf("Ethernet interface %s does not exists", ifname)
+		return newValidationErr(ValidationReasonUnavailableC, fmt.Errorf("Ethernet interface %s does not exists", ifname))
 	}
 
 	var err error
@@ -247,7 +247,7 @@ func (this *configLookupTablesT) checkDependenciesForSetAggIntfMember(aggIfname
 func (this *configLookupTablesT) checkDependenciesForDeleteAggIntfMember(aggIfname string, ifname string) error {
 	intfIdx, exists := this.idxByEthIfname[ifname]
 	if !exists {
-		return fmt.Errorf("Ethernet interface %s does not exist", ifname)
+		return newValidationErr(ValidationReasonUnavailableC, fmt.Errorf("Ethernet interface %s does not exist", ifname))
 	}
 
 	var err error
@@ -345,12 +345,12 @@ func (this *configLookupTablesT) checkDependenciesForDeleteAggIntf(aggIfname str
 func (this *configLookupTablesT) setAggIntfMember(aggIfname string, ifname string) error {
 	intfIdx, exists := this.idxByEthIfname[ifname]
 	if !exists {
-		return fmt.Errorf("Ethernet interface %s does not exists", ifname)
+		return newValidationErr(ValidationReasonUnavailableC, fmt.Errorf("Ethernet interface %s does not exists", ifname))
 	}
 
 	lagIdx, exists := this.idxByAggIfname[aggIfname]
 	if !exists {
-		return fmt.Errorf("LAG interface %s does not exist", aggIfname)
+		return newValidationErr(ValidationReasonUnavailableC, fmt.Errorf("LAG interface %s does not exist", aggIfname))
 	}
 
 	if _, exists := this.ethByAgg[lagIdx]; !exists {
@@ -368,12 +368,12 @@ func (this *configLookupTablesT) setAggIntfMember(aggIfname string, ifname strin
 func (this *configLookupTablesT) deleteAggIntfMember(aggIfname string, ifname string) error {
 	intfIdx, exists := this.idxByEthIfname[ifname]
 	if !exists {
-		return fmt.Errorf("Ethernet interface %s does not exist", ifname)
+		return newValidationErr(ValidationReasonUnavailableC, fmt.Errorf("Ethernet interface %s does not exist", ifname))
 	}
 
 	lagIdx, exists := this.idxByAggIfname[aggIfname]
 	if !exists {
-		return fmt.Errorf("LAG %s does not exist", aggIfname)
+		return newValidationErr(ValidationReasonUnavailableC, fmt.Errorf("LAG %s does not exist", aggIfname))
 	}
 
 	if this.aggByEth[intfIdx] != lagIdx {
@@ -401,7 +401,7 @@ func (this *configLookupTablesT) setAggIntf(aggIfname string) error {
 func (this *configLookupTablesT) deleteAggIntf(aggIfname string) error {
 	lagIdx, exists := this.idxByAggIfname[aggIfname]
 	if !exists {
-		return fmt.Errorf("LAG %s does not exist", aggIfname)
+		return newValidationErr(ValidationReasonUnavailableC, fmt.Errorf("LAG %s does not exist", aggIfname))
 	}
 
 	delete(this.idxByAggIfname, aggIfname)
@@ -718,7 +718,7 @@ func (table *configLookupTablesT) addNewAggIntfIfItDoesNotExist(ifname string) e
 func (table *configLookupTablesT) setVlanModeEthIntf(ifname string, vlanMode oc.E_OpenconfigVlan_VlanModeType) error {
 	intfIdx, exists := table.idxByEthIfname[ifname]
 	if !exists {
-		return fmt.Errorf("Ethernet interface %s does not exist", ifname)
+		return newValidationErr(ValidationReasonUnavailableC, fmt.Errorf("Ethernet interface %s does not exist", ifname))
 	}
 	table.vlanModeByEth[intfIdx] = vlanMode
 
@@ -728,7 +728,7 @@ func (table *configLookupTablesT) setVlanModeEthIntf(ifname string, vlanMode oc.
 func (this *configLookupTablesT) deleteAccessVlanEthIntf(ifname string, vidDelete lib.VidT) error {
 	intfIdx, exists := this.idxByEthIfname[ifname]
 	if !exists {
-		return fmt.Errorf("Ethernet interface %s does not exist", ifname)
+		return newValidationErr(ValidationReasonUnavailableC, fmt.Errorf("Ethernet interface %s does not exist", ifname))
 	}
 
 	vid, exists := this.vlanAccessByEth[intfIdx]
@@ -756,7 +756,7 @@ func (table *configLookupTablesT) setNativeVlanEthIntf(ifname string, vid lib.Vi
 func (this *configLookupTablesT) deleteNativeVlanEthIntf(ifname string, vidDelete lib.VidT) error {
 	intfIdx, exists := this.idxByEthIfname[ifname]
 	if !exists {
-		return fmt.Errorf("Ethernet interface %s does not exist", ifname)
+		return newValidationErr(ValidationReasonUnavailableC, fmt.Errorf("Ethernet interface %s does not exist", ifname))
 	}
 
 	vid, exists := this.vlanNativeByEth[intfIdx]
@@ -793,7 +793,7 @@ func (table *configLookupTablesT) setTrunkVlanEthIntf(ifname string, vid lib.Vid
 func (this *configLookupTablesT) deleteTrunkVlanEthIntf(ifname string, vidDelete lib.VidT) error {
 	intfIdx, exists := this.idxByEthIfname[ifname]
 	if !exists {
-		return fmt.Errorf("Ethernet interface %s does not exist", ifname)
+		return newValidationErr(ValidationReasonUnavailableC, fmt.Errorf("Ethernet interface %s does not exist", ifname))
 	}
 
 	vlans, exists := this.vlanTrunkByEth[intfIdx]
@@ -1012,7 +1012,7 @@ func (t *configLookupTablesT) parseInterfaceAsLagMember(ifname string, eth *oc.I
 	}
 	lagIdx, exists := t.idxByAggIfname[aggIfname]
 	if !exists {
-		return fmt.Errorf("Invalid LAG %s on interface %s: LAG not exists", aggIfname, ifname)
+		return newValidationErr(ValidationReasonUnavailableC, fmt.Errorf("Invalid LAG %s on interface %s: LAG not exists", aggIfname, ifname))
 	}
 
 	intfIdx := t.idxByEthIfname[ifname]
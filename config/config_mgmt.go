@@ -10,6 +10,7 @@ import (
 	"opennos-mgmt/utils"
 	"regexp"
 	"strconv"
+	"sync/atomic"
 	"time"
 
 	cmd "opennos-mgmt/config/command"
@@ -101,8 +102,11 @@ type cmdByNameT map[string]cmd.CommandI
 
 // ConfigMngrT is responisble for management of device configuration
 type ConfigMngrT struct {
+	// Number of changes of config applied by clients, accessed atomically, so it is the first field to be aligned
+	clientConfigGeneration  uint64
 	configLookupTbl         *configLookupTablesT
 	runningConfig           ygot.ValidatedGoStruct
+	prevRunningConfig       ygot.ValidatedGoStruct // running config before the last commit
 	cmdByName               [maxNumberOfActionsInTransactionC]cmdByNameT
 	ethSwitchMgmtClientConn *grpc.ClientConn
 	ethSwitchMgmtClient     *mgmt.EthSwitchMgmtClient
@@ -114,27 +118,65 @@ type ConfigMngrT struct {
 	transConfirmationTimeoutCtx context.Context
 	transConfirmationCancel     context.CancelFunc
 	transCandidateConfig        *ygot.ValidatedGoStruct
-	transHasBeenStarted         bool // marks if transaction has been started
+	transHasBeenStarted         bool            // marks if transaction has been started
+	transCommitID               string          // ID of commit awaiting confirmation, if requested by client
+	transSession                SessionT        // Client session requesting changes of config
+	configLock                  *configLockT    // Exclusive lock of config held by client session
+	resumedCommit               *resumedCommitT // Commit awaiting confirmation since before restart
+	commitHistory               *commitHistoryT
+	scheduledCommits            map[uint32]*scheduledCommitT // Commits awaiting their time, by ID
+	lastScheduledCommit         uint32
+	changelogNotifier           ChangelogNotifierT
+	runningConfigNotifier       RunningConfigNotifierT
+	runningConfigNotifications  notificationQueueT   // Running configs to notify about, in order of replacement
+	isHandlingClientChange      bool                 // Marks if change of config requested by client is being handled
+	commitQueue                 chan *commitRequestT // Requests handled by commit worker one by one
 }
 
+// ChangelogNotifierT is called with changelog of every config committed into running config
+type ChangelogNotifierT func(changelog diff.Changelog, runningConfig ygot.ValidatedGoStruct)
+
+// RunningConfigNotifierT is called with running config replaced by device on its own, i.e. restored by
+// rollback of changes or changed by scheduled commit. Notification is delivered asynchronously, so
+// isCurrent reports if running config has not been superseded by config changed by client since.
+type RunningConfigNotifierT func(runningConfig ygot.ValidatedGoStruct, isCurrent func() bool)
+
 // NewConfigMngrT creates instance of ConfigMngrT object
 func NewConfigMngrT() *ConfigMngrT {
-	return &ConfigMngrT{
+	configMngr := &ConfigMngrT{
 		configLookupTbl:     newConfigLookupTables(),
 		transHasBeenStarted: false,
+		commitHistory:       newCommitHistory(),
+		scheduledCommits:    make(map[uint32]*scheduledCommitT),
+		commitQueue:         make(chan *commitRequestT, commitQueueSizeC),
 	}
+
+	go configMngr.runCommitWorker()
+	return configMngr
 }
 
-func (this *ConfigMngrT) NewTransaction() error {
+func (this *ConfigMngrT) newTransaction() error {
 	if this.isTransPending() {
 		return errors.New("Transaction is already active")
 	}
+	if this.resumedCommit != nil {
+		return fmt.Errorf("Commit %q is awaiting confirmation", this.resumedCommit.id)
+	}
 	conn, err := grpc.Dial(fmt.Sprintf(":%d", serv_param.MgmtListeningTcpPortC), grpc.WithInsecure(), grpc.WithBlock())
 	if err != nil {
 		log.Errorf("Failed to dial into the switch gRPC server: %v", err)
 		return err
 	}
 	ethSwitchMgmtClient := mgmt.NewEthSwitchMgmtClient(conn)
+	this.initTransCmds()
+	this.ethSwitchMgmtClientConn = conn
+	this.ethSwitchMgmtClient = &ethSwitchMgmtClient
+	this.transHasBeenStarted = true
+	return nil
+}
+
+// initTransCmds prepares empty set of commands of transaction
+func (this *ConfigMngrT) initTransCmds() {
 	nilCmd := &cmd.NilCmdT{}
 	// TODO: Check if it is still required?
 	var i OrdinalNumberT
@@ -145,13 +187,9 @@ func (this *ConfigMngrT) NewTransaction() error {
 
 	this.transConfigLookupTbl = this.configLookupTbl.makeCopy()
 	this.transCmdList = list.New()
-	this.ethSwitchMgmtClientConn = conn
-	this.ethSwitchMgmtClient = &ethSwitchMgmtClient
-	this.transHasBeenStarted = true
-	return nil
 }
 
-func (this *ConfigMngrT) Commit() error {
+func (this *ConfigMngrT) commit() error {
 	if !this.isTransPending() {
 		return errors.New("Transaction has not been started")
 	}
@@ -164,7 +202,7 @@ func (this *ConfigMngrT) Commit() error {
 				undoCmd := un.Value.(cmd.CommandI)
 				undoCmd.Undo()
 			}
-			this.DiscardOrFinishTrans()
+			this.discardOrFinishTrans()
 			return err
 		}
 	}
@@ -172,7 +210,7 @@ func (this *ConfigMngrT) Commit() error {
 	return nil
 }
 
-func (this *ConfigMngrT) Rollback() error {
+func (this *ConfigMngrT) rollback() error {
 	if !this.isTransPending() {
 		return errors.New("Transaction has not been started")
 	}
@@ -191,26 +229,221 @@ func (this *ConfigMngrT) Rollback() error {
 		}
 	}
 
-	this.DiscardOrFinishTrans()
+	this.discardOrFinishTrans()
 	return err
 }
 
-func (this *ConfigMngrT) CommitConfirm() error {
-	return this.Commit()
+func (this *ConfigMngrT) commitConfirm() error {
+	return this.commit()
 }
 
-func (this *ConfigMngrT) Confirm() error {
+func (this *ConfigMngrT) confirm() error {
+	if this.resumedCommit != nil {
+		return this.confirmResumedCommit()
+	}
+
 	if !this.transHasBeenStarted {
 		return errors.New("Transaction has not been started")
 	}
 
 	candidateConfig := this.transCandidateConfig
 	this.configLookupTbl = this.transConfigLookupTbl.makeCopy()
-	this.DiscardOrFinishTrans()
-	return this.CommitCandidateConfig(candidateConfig)
+	this.discardOrFinishTrans()
+	if err := this.commitCandidateConfig(candidateConfig); err != nil {
+		return err
+	}
+
+	removePendingCommit()
+	return nil
+}
+
+func (this *ConfigMngrT) confirmCommit(commitID string) error {
+	if err := this.checkConfigLock(); err != nil {
+		return err
+	}
+
+	if err := this.checkPendingCommit(commitID); err != nil {
+		return err
+	}
+
+	this.transConfirmationCancel()
+	return this.confirm()
+}
+
+func (this *ConfigMngrT) cancelCommit(commitID string) error {
+	if err := this.checkConfigLock(); err != nil {
+		return err
+	}
+
+	if err := this.checkPendingCommit(commitID); err != nil {
+		return err
+	}
+
+	this.transConfirmationCancel()
+	return this.rollbackPendingCommit()
+}
+
+func (this *ConfigMngrT) setCommitRollbackDuration(commitID string, rollbackDuration time.Duration) error {
+	if err := this.checkConfigLock(); err != nil {
+		return err
+	}
+
+	if err := this.checkPendingCommit(commitID); err != nil {
+		return err
+	}
+
+	this.transConfirmationCancel()
+	if err := this.persistPendingCommit(rollbackDuration); err != nil {
+		log.Errorf("Failed to persist pending commit: %s", err)
+	}
+
+	this.startConfirmationTimeout(rollbackDuration)
+	return nil
+}
+
+// discardChanges withdraws changes of pending commit, which has not been confirmed yet
+func (this *ConfigMngrT) discardChanges() error {
+	if !this.isCommitAwaitingConfirmation() {
+		return newValidationErr(ValidationReasonUnavailableC, errors.New("There are no changes awaiting confirmation"))
+	}
+
+	this.transConfirmationCancel()
+	if err := this.rollbackPendingCommit(); err != nil {
+		return err
+	}
+
+	log.Infof("Discarded changes awaiting confirmation")
+	this.notifyRunningConfig()
+	return nil
+}
+
+// rollbackLastCommit reverts changes of the last commit applied permanently
+func (this *ConfigMngrT) rollbackLastCommit() error {
+	if this.isTransPending() {
+		return newValidationErr(ValidationReasonDependencyC, errors.New("Cannot rollback last commit while there are changes awaiting confirmation"))
+	}
+
+	if this.prevRunningConfig == nil {
+		return newValidationErr(ValidationReasonUnavailableC, errors.New("There is no commit to rollback"))
+	}
+
+	if err := this.rollbackToConfig(this.prevRunningConfig); err != nil {
+		return err
+	}
+
+	log.Infof("Rolled back the last commit")
+	this.notifyRunningConfig()
+	return nil
 }
 
-func (this *ConfigMngrT) DiscardOrFinishTrans() error {
+// rollbackToCommit restores config committed by commit of given ID
+func (this *ConfigMngrT) rollbackToCommit(commitID uint32) error {
+	if this.isTransPending() {
+		return newValidationErr(ValidationReasonDependencyC, fmt.Errorf("Cannot rollback to commit %d while there are changes awaiting confirmation", commitID))
+	}
+
+	record := this.commitHistory.find(commitID)
+	if record == nil {
+		return newValidationErr(ValidationReasonUnavailableC, fmt.Errorf("Commit %d is not available in history of commits", commitID))
+	}
+
+	// History has to keep its own copy, because restored config becomes running one
+	config, err := ygot.DeepCopy(record.config)
+	if err != nil {
+		return err
+	}
+
+	if err = this.rollbackToConfig(config.(ygot.ValidatedGoStruct)); err != nil {
+		return err
+	}
+
+	log.Infof("Rolled back to commit %d", commitID)
+	this.notifyRunningConfig()
+	return nil
+}
+
+// rollbackToConfig replays diff of running config with given one as a new commit
+func (this *ConfigMngrT) rollbackToConfig(config ygot.ValidatedGoStruct) error {
+	changelog, err := this.diffRunningConfigWithCandidateConfig(&config)
+	if err != nil {
+		return err
+	}
+
+	diffChangelog, err := this.extendChangelog(&changelog)
+	if err != nil {
+		return err
+	}
+
+	// Parameters of transaction are restored along with the config
+	if err = this.markTransParamChangesAsProcessed(diffChangelog); err != nil {
+		return err
+	}
+
+	_, err = this.commitDiffChangelog(diffChangelog, &config, oc.OpenconfigManagement_TRANS_TYPE_TRANS_COMMIT, 0)
+	return err
+}
+
+// notifyRunningConfig notifies about running config restored by rollback or changed by
+// scheduled commit. It is notified asynchronously, because rollback may be requested by
+// the notified party itself, so notifier gets the snapshot of running config taken here.
+// Snapshot is tagged with generation of config of clients, which it supersedes. Change
+// being handled supersedes the current generation, once it is applied by client.
+func (this *ConfigMngrT) notifyRunningConfig() {
+	notifier := this.runningConfigNotifier
+	if notifier == nil {
+		return
+	}
+
+	configCopy, err := ygot.DeepCopy(this.runningConfig)
+	if err != nil {
+		log.Errorf("Failed to copy running config to notify about: %s", err)
+		return
+	}
+
+	runningConfig := configCopy.(ygot.ValidatedGoStruct)
+	generation := atomic.LoadUint64(&this.clientConfigGeneration)
+	if this.isHandlingClientChange {
+		generation++
+	}
+	isCurrent := func() bool {
+		return atomic.LoadUint64(&this.clientConfigGeneration) <= generation
+	}
+	this.runningConfigNotifications.push(func() {
+		notifier(runningConfig, isCurrent)
+	})
+}
+
+// rollbackPendingCommit withdraws changes of commit awaiting confirmation
+func (this *ConfigMngrT) rollbackPendingCommit() error {
+	if this.resumedCommit != nil {
+		return this.rollbackResumedCommit()
+	}
+
+	if err := this.rollback(); err != nil {
+		return err
+	}
+
+	removePendingCommit()
+	return nil
+}
+
+func (this *ConfigMngrT) isCommitAwaitingConfirmation() bool {
+	return (this.isTransPending() && this.transConfirmationCancel != nil) || this.resumedCommit != nil
+}
+
+func (this *ConfigMngrT) checkPendingCommit(commitID string) error {
+	if !this.isCommitAwaitingConfirmation() {
+		return errors.New("There is no commit awaiting confirmation")
+	}
+
+	if this.transCommitID != commitID {
+		return fmt.Errorf("Commit %q is not awaiting confirmation, pending commit is %q", commitID, this.transCommitID)
+	}
+
+	return nil
+}
+
+func (this *ConfigMngrT) discardOrFinishTrans() error {
 	if !this.isTransPending() {
 		return errors.New("Transaction has not been started")
 	}
@@ -222,6 +455,7 @@ func (this *ConfigMngrT) DiscardOrFinishTrans() error {
 	this.transConfirmationTimeoutCtx = nil
 	this.transConfirmationCancel = nil
 	this.transCandidateConfig = nil
+	this.transCommitID = ""
 	this.transHasBeenStarted = false
 	return nil
 }
@@ -237,8 +471,19 @@ func getBreakoutMasterPort(ifname string) (string, bool) {
 	return "", false
 }
 
-func (this *ConfigMngrT) LoadConfig(model *gnmi.Model, config []byte) error {
+func (this *ConfigMngrT) loadConfig(model *gnmi.Model, config []byte) error {
 	var err error
+	pending, err := loadPendingCommit()
+	if err != nil {
+		return err
+	}
+
+	if pending != nil {
+		// Device has been left with config awaiting confirmation
+		log.Infof("Found commit %q awaiting confirmation before restart", pending.ID)
+		config = pending.CommitConfig
+	}
+
 	configModel, err := model.NewConfigStruct(config)
 	if err != nil {
 		return err
@@ -256,10 +501,9 @@ func (this *ConfigMngrT) LoadConfig(model *gnmi.Model, config []byte) error {
 		}
 
 		intf := device.Interface[ifname]
-		if agg := intf.GetAggregation(); agg != nil {
-			if agg.GetLagType() == oc.OpenconfigIfAggregate_AggregationType_UNSET {
-				return fmt.Errorf("LAG type for aggregate interface %s cannot be unset",
-					ifname)
+		if intf.GetAggregation() != nil {
+			if err = checkLacpPairing(device, ifname); err != nil {
+				return err
 			}
 
 			if err = this.configLookupTbl.addNewAggIntfIfItDoesNotExist(ifname); err != nil {
@@ -272,6 +516,10 @@ func (this *ConfigMngrT) LoadConfig(model *gnmi.Model, config []byte) error {
 		}
 	}
 
+	if err = checkLacpIntfsPairing(device); err != nil {
+		return err
+	}
+
 	for ethIfname := range this.configLookupTbl.idxByEthIfname {
 		masterPort, exists := getBreakoutMasterPort(ethIfname)
 		if exists { // breakout mode enable
@@ -338,13 +586,17 @@ func (this *ConfigMngrT) LoadConfig(model *gnmi.Model, config []byte) error {
 		return err
 	}
 
-	return this.CommitCandidateConfig(&configModel)
+	if pending != nil {
+		return this.resumePendingCommit(model, pending, configModel)
+	}
+
+	return this.commitCandidateConfig(&configModel)
 }
 
 func (this *ConfigMngrT) configureDevice(configModel *ygot.ValidatedGoStruct) error {
 	device := (*configModel).(*oc.Device)
 	var err error
-	if err = this.NewTransaction(); err != nil {
+	if err = this.newTransaction(); err != nil {
 		return err
 	}
 
@@ -360,6 +612,10 @@ func (this *ConfigMngrT) configureDevice(configModel *ygot.ValidatedGoStruct) er
 		return err
 	}
 
+	if err = this.setLacp(device); err != nil {
+		return err
+	}
+
 	if err = this.setAggIntfMember(device); err != nil {
 		return err
 	}
@@ -372,11 +628,11 @@ func (this *ConfigMngrT) configureDevice(configModel *ygot.ValidatedGoStruct) er
 		return err
 	}
 
-	if err = this.Commit(); err != nil {
+	if err = this.commit(); err != nil {
 		return err
 	}
 
-	return this.DiscardOrFinishTrans()
+	return this.discardOrFinishTrans()
 }
 
 func isPortSplitted(device *oc.Device, ethIfname string) bool {
@@ -444,19 +700,84 @@ func (this *ConfigMngrT) appendCmdToTransaction(idName string, cmdAdd cmd.Comman
 	return nil
 }
 
-// TODO: Maybe move it into DiscardOrFinishTrans()
-func (this *ConfigMngrT) CommitCandidateConfig(candidateConfig *ygot.ValidatedGoStruct) error {
+// TODO: Maybe move it into discardOrFinishTrans()
+func (this *ConfigMngrT) commitCandidateConfig(candidateConfig *ygot.ValidatedGoStruct) error {
 	// TODO: Consider if we should commit transConfigLookupTable here?
 	// TODO: Make deep copy?
+	var changelog diff.Changelog
+	if this.runningConfig != nil {
+		var err error
+		if changelog, err = this.diffRunningConfigWithCandidateConfig(candidateConfig); err != nil {
+			log.Errorf("Failed to get diff of running config with candidate config: %s", err)
+			return err
+		}
+	}
+
+	prevRunningConfig := this.runningConfig
 	if err := copier.Copy(&this.runningConfig, &candidateConfig); err != nil {
 		return err
 	}
 
-	return gnmi.SaveConfigFile(this.runningConfig, startupConfigFilenameC)
+	comment, label := transCommitDescFromChangelog(changelog)
+	// Candidate is kept by client as its view of running config
+	clearTransCommitDesc(*candidateConfig)
+	clearTransCommitDesc(this.runningConfig)
+
+	if err := gnmi.SaveConfigFile(this.runningConfig, startupConfigFilenameC); err != nil {
+		return err
+	}
+
+	this.prevRunningConfig = prevRunningConfig
+
+	if err := this.recordCommit(changelog, comment, label); err != nil {
+		// Config has been already applied, so just lose the track of it
+		log.Errorf("Failed to record commit in history: %s", err)
+	}
+
+	if this.changelogNotifier != nil && len(changelog) > 0 {
+		this.changelogNotifier(changelog, this.runningConfig)
+	}
+	return nil
 }
 
-func (this *ConfigMngrT) GetDiffRunningConfigWithCandidateConfig(candidateConfig *ygot.ValidatedGoStruct) (diff.Changelog, error) {
-	return diff.Diff(this.runningConfig, *candidateConfig)
+// recordCommit adds running config to history of commits and exposes the history in running config
+func (this *ConfigMngrT) recordCommit(changelog diff.Changelog, comment string, label string) error {
+	record, err := this.commitHistory.add(this.transSession.User, comment, label, changelog, this.runningConfig)
+	if err != nil {
+		return err
+	}
+
+	commits, err := this.commitHistory.toOc()
+	if err != nil {
+		return err
+	}
+
+	scheduledCommits, err := this.scheduledCommitsToOc()
+	if err != nil {
+		return err
+	}
+
+	device := this.runningConfig.(*oc.Device)
+	device.GetOrCreateManagement().Commit = commits
+	device.GetOrCreateManagement().ScheduledCommit = scheduledCommits
+	log.Infof("Recorded commit %d of user %q with %d changes, label %q: %s", record.id, record.user, len(changelog),
+		record.label, record.comment)
+	return nil
+}
+
+// GetDiffConfigs returns changes of config toConfig against config fromConfig, except history of
+// commits and scheduled commits maintained by device
+func (this *ConfigMngrT) GetDiffConfigs(fromConfig ygot.ValidatedGoStruct, toConfig ygot.ValidatedGoStruct) (diff.Changelog, error) {
+	changelog, err := diff.Diff(fromConfig, toConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return filterMgmtStateChanges(changelog), nil
+}
+
+func (this *ConfigMngrT) diffRunningConfigWithCandidateConfig(candidateConfig *ygot.ValidatedGoStruct) (diff.Changelog, error) {
+	return this.GetDiffConfigs(this.runningConfig, *candidateConfig)
 }
 
 func (this *ConfigMngrT) isEthIntfAvailable(ifname string) bool {
@@ -660,119 +981,337 @@ func isDeleteDiffChange(change *diff.Change) bool {
 	return true
 }
 
-func (this *ConfigMngrT) CommitChangelog(changelog *diff.Changelog, candidateConfig *ygot.ValidatedGoStruct) error {
-	var err error
+func (this *ConfigMngrT) extendChangelog(changelog *diff.Changelog) (*DiffChangelogMgmtT, error) {
 	if newChanges, err := extractCreateEthIntfParams(changelog); err == nil {
 		*changelog = append(*changelog, *newChanges...)
 	} else {
-		return fmt.Errorf("Failed to extract new Ethernet interface parameters from changelog: %s", err)
+		return nil, fmt.Errorf("Failed to extract new Ethernet interface parameters from changelog: %s", err)
 	}
 
 	if newChanges, err := extractDeleteEthIntfParams(changelog); err == nil {
 		*changelog = append(*changelog, *newChanges...)
 	} else {
-		return fmt.Errorf("Failed to extract delete Ethernet interface parameters from changelog: %s", err)
+		return nil, fmt.Errorf("Failed to extract delete Ethernet interface parameters from changelog: %s", err)
 	}
 
 	if newChanges, err := extractCreateAggIntfAggregationParams(changelog); err == nil {
 		*changelog = append(*changelog, *newChanges...)
 	} else {
-		return fmt.Errorf("Failed to extract create agregate interface parameters from changelog: %s", err)
+		return nil, fmt.Errorf("Failed to extract create agregate interface parameters from changelog: %s", err)
+	}
+
+	if newChanges, err := extractLacpParams(changelog); err == nil {
+		*changelog = append(*changelog, *newChanges...)
+	} else {
+		return nil, fmt.Errorf("Failed to extract LACP parameters from changelog: %s", err)
 	}
 
 	diffChangelog := NewDiffChangelogMgmtT(changelog)
-	currentDefaultConfigAction := this.getCurrentTransDefaultConfigAction()
 	if change, exists := findDisallowedManagementTreeNodeDeleteOperation(diffChangelog); exists {
-		return fmt.Errorf("Delete operation on tree node %q is disallowed", change.Path)
+		return nil, newValidationErr(ValidationReasonUnsupportedC, fmt.Errorf("Delete operation on tree node %q is disallowed", change.Path))
+	}
+
+	return diffChangelog, nil
+}
+
+func (this *ConfigMngrT) commitChangelog(changelog *diff.Changelog, candidateConfig *ygot.ValidatedGoStruct) (*gnmi.ApplyResult, error) {
+	currentDefaultConfigAction := this.getCurrentTransDefaultConfigAction()
+	diffChangelog, err := this.extendChangelog(changelog)
+	if err != nil {
+		return nil, err
 	}
 
 	// Stub for marking processed change
 	_, err = this.findTransDefaultConfigActionChange(diffChangelog)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	configAction, err := this.findTransConfigActionChange(diffChangelog)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	commitConfirmTimeout, err := this.findTransCommitConfirmTimeoutChange(diffChangelog)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	rollbackCommitID, err := this.findTransRollbackCommitIdChange(diffChangelog)
+	if err != nil {
+		return nil, err
+	}
+
+	commitAt, err := this.findTransCommitAtChange(diffChangelog)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduledCommitID, err := this.findTransScheduledCommitIdChange(diffChangelog)
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err := this.findTransCommentChange(diffChangelog)
+	if err != nil {
+		return nil, err
+	}
+
+	label, err := this.findTransLabelChange(diffChangelog)
+	if err != nil {
+		return nil, err
 	}
 
-	if configAction == oc.OpenconfigManagement_TRANS_TYPE_UNSET {
+	log.Infof("Transaction of session %s with config action %s, label %q: %s", this.transSession, configAction, label, comment)
+	switch configAction {
+	case oc.OpenconfigManagement_TRANS_TYPE_TRANS_DISCARD_CHANGES, oc.OpenconfigManagement_TRANS_TYPE_TRANS_ROLLBACK,
+		oc.OpenconfigManagement_TRANS_TYPE_TRANS_LOCK, oc.OpenconfigManagement_TRANS_TYPE_TRANS_UNLOCK,
+		oc.OpenconfigManagement_TRANS_TYPE_TRANS_BREAK_LOCK, oc.OpenconfigManagement_TRANS_TYPE_TRANS_CANCEL_SCHEDULED_COMMIT:
+		if !diffChangelog.isProcessed() {
+			return nil, newValidationErr(ValidationReasonUnsupportedC, fmt.Errorf("Config action %s cannot be combined with changes of config",
+				configAction))
+		}
+
+		if isConfigLockAction(configAction) {
+			return nil, this.doConfigLockAction(configAction, candidateConfig)
+		}
+
+		if err = this.checkConfigLock(); err != nil {
+			return nil, err
+		}
+
+		if configAction == oc.OpenconfigManagement_TRANS_TYPE_TRANS_DISCARD_CHANGES {
+			return nil, this.discardChanges()
+		}
+
+		if configAction == oc.OpenconfigManagement_TRANS_TYPE_TRANS_CANCEL_SCHEDULED_COMMIT {
+			if scheduledCommitID == nil {
+				return nil, newValidationErr(ValidationReasonUnavailableC, errors.New("Scheduled commit ID is required to cancel scheduled commit"))
+			}
+
+			return nil, this.cancelScheduledCommit(*scheduledCommitID, candidateConfig)
+		}
+
+		if rollbackCommitID != nil {
+			return nil, this.rollbackToCommit(*rollbackCommitID)
+		}
+
+		return nil, this.rollbackLastCommit()
+	case oc.OpenconfigManagement_TRANS_TYPE_UNSET:
 		configAction = currentDefaultConfigAction
 	}
 
-	if configAction != oc.OpenconfigManagement_TRANS_TYPE_TRANS_DRY_RUN {
-		if (configAction == oc.OpenconfigManagement_TRANS_TYPE_TRANS_CONFIRM) && this.isTransPending() {
-			this.transConfirmationCancel()
-			this.Confirm()
-			return nil
+	if err = this.checkConfigLock(); err != nil {
+		return nil, err
+	}
+
+	if commitAt != nil {
+		if configAction != oc.OpenconfigManagement_TRANS_TYPE_TRANS_COMMIT {
+			return nil, newValidationErr(ValidationReasonUnsupportedC, fmt.Errorf("Config action %s cannot be scheduled", configAction))
 		}
 
-		if err = this.NewTransaction(); err != nil {
+		return nil, this.scheduleCommit(changelog, diffChangelog, candidateConfig, time.Unix(0, int64(*commitAt)))
+	}
+
+	if (configAction == oc.OpenconfigManagement_TRANS_TYPE_TRANS_CONFIRM) && this.isCommitAwaitingConfirmation() {
+		this.transConfirmationCancel()
+		return nil, this.confirm()
+	}
+
+	return this.commitDiffChangelog(diffChangelog, candidateConfig, configAction,
+		time.Duration(commitConfirmTimeout)*time.Second)
+}
+
+func (this *ConfigMngrT) commitChangelogConfirmed(changelog *diff.Changelog, candidateConfig *ygot.ValidatedGoStruct, commitID string, rollbackDuration time.Duration) error {
+	if err := this.checkConfigLock(); err != nil {
+		return err
+	}
+
+	if this.isTransPending() {
+		return fmt.Errorf("Commit %q is awaiting confirmation", this.transCommitID)
+	}
+
+	diffChangelog, err := this.extendChangelog(changelog)
+	if err != nil {
+		return err
+	}
+
+	// Parameters of transaction are given by client, so just mark changes of them as processed
+	if _, err = this.findTransDefaultConfigActionChange(diffChangelog); err != nil {
+		return err
+	}
+
+	if _, err = this.findTransConfigActionChange(diffChangelog); err != nil {
+		return err
+	}
+
+	commitConfirmTimeout, err := this.findTransCommitConfirmTimeoutChange(diffChangelog)
+	if err != nil {
+		return err
+	}
+
+	if _, err = this.findTransRollbackCommitIdChange(diffChangelog); err != nil {
+		return err
+	}
+
+	commitAt, err := this.findTransCommitAtChange(diffChangelog)
+	if err != nil {
+		return err
+	}
+
+	if commitAt != nil {
+		return newValidationErr(ValidationReasonUnsupportedC, errors.New("Commit awaiting confirmation cannot be scheduled"))
+	}
+
+	if _, err = this.findTransScheduledCommitIdChange(diffChangelog); err != nil {
+		return err
+	}
+
+	if _, err = this.findTransCommentChange(diffChangelog); err != nil {
+		return err
+	}
+
+	if _, err = this.findTransLabelChange(diffChangelog); err != nil {
+		return err
+	}
+
+	if rollbackDuration == 0 {
+		rollbackDuration = time.Duration(commitConfirmTimeout) * time.Second
+	}
+
+	// ID is persisted along with the commit
+	this.transCommitID = commitID
+	_, err = this.commitDiffChangelog(diffChangelog, candidateConfig, oc.OpenconfigManagement_TRANS_TYPE_TRANS_COMMIT_CONFIRM, rollbackDuration)
+	return err
+}
+
+func (this *ConfigMngrT) commitDiffChangelog(diffChangelog *DiffChangelogMgmtT, candidateConfig *ygot.ValidatedGoStruct,
+	configAction oc.E_OpenconfigManagement_TRANS_TYPE, commitConfirmTimeout time.Duration) (*gnmi.ApplyResult, error) {
+	var err error
+	if configAction != oc.OpenconfigManagement_TRANS_TYPE_TRANS_DRY_RUN {
+		if err = this.newTransaction(); err != nil {
 			log.Errorf("Failed to start new transaction")
-			return err
+			return nil, err
 		}
 	} else {
+		// Commands of pending transaction cannot be overwritten
+		if this.isTransPending() || this.resumedCommit != nil {
+			return nil, newValidationErr(ValidationReasonDependencyC, errors.New("Cannot dry run changes while there are changes awaiting confirmation"))
+		}
+
 		log.Infof("Dry running transaction")
-		this.transConfigLookupTbl = this.configLookupTbl.makeCopy()
+		this.initTransCmds()
 	}
 
 	this.transCandidateConfig = candidateConfig
 
 	if err = this.parseChangelogAndConvertToCommands(diffChangelog); err != nil {
-		this.DiscardOrFinishTrans()
-		return err
+		this.discardOrFinishTrans()
+		return nil, err
 	}
 
 	rawRunningConfig, err := gnmi.ConvertYgotGoStructIntoJsonByteStream(this.runningConfig)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	rawCandidateConfig, err := gnmi.ConvertYgotGoStructIntoJsonByteStream(*candidateConfig)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	configJsonDiff, err := utils.GetJsonDiff(rawRunningConfig, rawCandidateConfig)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if configAction == oc.OpenconfigManagement_TRANS_TYPE_TRANS_COMMIT_CONFIRM {
-		if err := this.CommitConfirm(); err != nil {
-			return err
+		if err := this.commitConfirm(); err != nil {
+			return nil, err
 		}
 
-		this.transConfirmationTimeoutCtx, this.transConfirmationCancel = context.WithCancel(context.Background())
-		go this.startCountingForConfirmationTimeout(&this.transConfirmationTimeoutCtx, commitConfirmTimeout)
+		// Without persisted commit the changes would not be withdrawn after restart
+		if err := this.persistPendingCommit(commitConfirmTimeout); err != nil {
+			log.Errorf("Failed to persist pending commit: %s", err)
+			this.rollback()
+			return nil, err
+		}
 
-		return fmt.Errorf("\nWaiting %d seconds for confirmation changes\n%s",
-			commitConfirmTimeout, configJsonDiff)
+		this.startConfirmationTimeout(commitConfirmTimeout)
+		log.Infof("Waiting %s for confirmation changes\n%s", commitConfirmTimeout, configJsonDiff)
+		return nil, nil
+	}
+
+	defer this.discardOrFinishTrans()
+	if configAction == oc.OpenconfigManagement_TRANS_TYPE_TRANS_COMMIT_BEST_EFFORT {
+		log.Infof("Commit changes in best-effort mode\n%s", configJsonDiff)
+		bestEffort, err := this.commitBestEffort(candidateConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		return &gnmi.ApplyResult{BestEffort: bestEffort}, nil
 	}
 
-	defer this.DiscardOrFinishTrans()
 	if configAction != oc.OpenconfigManagement_TRANS_TYPE_TRANS_DRY_RUN {
-		if err := this.Commit(); err != nil {
+		if err := this.commit(); err != nil {
 			log.Errorf("Failed to commit changes")
-			return err
+			return nil, err
 		}
 
 		this.configLookupTbl = this.transConfigLookupTbl.makeCopy()
-		this.DiscardOrFinishTrans()
+		this.discardOrFinishTrans()
 		log.Infof("Save new config")
-		return this.CommitCandidateConfig(candidateConfig)
+		return nil, this.commitCandidateConfig(candidateConfig)
 	}
 
-	// Deferred DiscardOrFinishTrans() will clean transConfigLookupTbl
+	dryRun := this.dryRunResult(diffChangelog, configJsonDiff)
+	log.Infof("Dry running: requested changes are valid, %d commands would be executed\n%s", len(dryRun.Commands), configJsonDiff)
+	// Deferred discardOrFinishTrans() will clean transConfigLookupTbl
 	this.transConfigLookupTbl = nil
-	// It is not really error, we just passing information that we have finished dry running with success
-	return fmt.Errorf("\nDry running: requested changes are valid\n%s", configJsonDiff)
+	this.transCmdList.Init()
+	return &gnmi.ApplyResult{DryRun: dryRun}, nil
+}
+
+// dryRunResult returns plan of commands queued in transaction and paths of changes requested by user
+func (this *ConfigMngrT) dryRunResult(diffChangelog *DiffChangelogMgmtT, configJsonDiff string) *gnmi.DryRunResult {
+	dryRun := &gnmi.DryRunResult{
+		Commands:    make([]gnmi.DryRunCommand, 0, this.transCmdList.Len()),
+		ChangePaths: make([][]string, 0, len(diffChangelog.Changes)),
+		Diff:        configJsonDiff,
+	}
+
+	for e := this.transCmdList.Front(); e != nil; e = e.Next() {
+		command := e.Value.(cmd.CommandI)
+		dryRun.Commands = append(dryRun.Commands, gnmi.DryRunCommand{
+			Name:  command.GetName(),
+			Phase: uint16(this.findTransCmdPhase(command)),
+		})
+	}
+
+	for _, ch := range diffChangelog.Changes {
+		// Parameters of transaction are not part of the plan
+		if len(ch.Change.Path) > 0 && ch.Change.Path[mgmtTransManagementPathItemIdxC] == mgmtTransManagementPathItemC {
+			continue
+		}
+		dryRun.ChangePaths = append(dryRun.ChangePaths, ch.Change.Path)
+	}
+
+	return dryRun
+}
+
+// findTransCmdPhase returns ordinal number of action performed by command in transaction
+func (this *ConfigMngrT) findTransCmdPhase(command cmd.CommandI) OrdinalNumberT {
+	var i OrdinalNumberT
+	for i = 0; i < maxNumberOfActionsInTransactionC; i++ {
+		for _, c := range this.cmdByName[i] {
+			if c == command {
+				return i
+			}
+		}
+	}
+
+	return unorderedActionInTransactionC
 }
 
 func (this *ConfigMngrT) parseChangelogAndConvertToCommands(diffChangelog *DiffChangelogMgmtT) error {
@@ -794,6 +1333,9 @@ func (this *ConfigMngrT) parseChangelogAndConvertToCommands(diffChangelog *DiffC
 		if err = this.processDeleteAggIntfMemberFromChangelog(diffChangelog); err != nil {
 			return err
 		}
+		if err = this.processDeleteLacpFromChangelog(diffChangelog); err != nil {
+			return err
+		}
 		if err = this.processDeleteAggIntfFromChangelog(diffChangelog); err != nil {
 			return err
 		}
@@ -816,6 +1358,9 @@ func (this *ConfigMngrT) parseChangelogAndConvertToCommands(diffChangelog *DiffC
 		if err = this.processSetAggIntfLagTypeFromChangelog(diffChangelog); err != nil {
 			return err
 		}
+		if err = this.processSetLacpFromChangelog(diffChangelog); err != nil {
+			return err
+		}
 		if err = this.processSetAggIntfMemberFromChangelog(diffChangelog); err != nil {
 			return err
 		}
@@ -857,15 +1402,31 @@ func (this *ConfigMngrT) parseChangelogAndConvertToCommands(diffChangelog *DiffC
 	return nil
 }
 
-func (this *ConfigMngrT) startCountingForConfirmationTimeout(ctx *context.Context, timeout uint16) {
+func (this *ConfigMngrT) startConfirmationTimeout(timeout time.Duration) {
+	this.transConfirmationTimeoutCtx, this.transConfirmationCancel = context.WithCancel(context.Background())
+	go this.startCountingForConfirmationTimeout(this.transConfirmationTimeoutCtx, timeout)
+}
+
+func (this *ConfigMngrT) startCountingForConfirmationTimeout(timeoutCtx context.Context, timeout time.Duration) {
 	select {
-	case <-time.After(time.Duration(timeout) * time.Second):
-		if err := this.Rollback(); err != nil {
-			log.Errorf("%s", err)
-		} else {
+	case <-time.After(timeout):
+		this.doInCommitWorker(func() error {
+			// Commit could be confirmed or cancelled while rollback has been queued
+			if timeoutCtx.Err() != nil {
+				log.Infof("Cancelled counting for commit confirmation timeout")
+				return nil
+			}
+
+			if err := this.rollbackPendingCommit(); err != nil {
+				log.Errorf("%s", err)
+				return err
+			}
+
 			log.Infof("Rollback changes")
-		}
-	case <-(*ctx).Done():
+			this.notifyRunningConfig()
+			return nil
+		})
+	case <-timeoutCtx.Done():
 		log.Infof("Cancelled counting for commit confirmation timeout")
 	}
 }
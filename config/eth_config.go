@@ -52,8 +52,8 @@ func (this *ConfigMngrT) validateSetEthIntfChange(changeItem *DiffChangeMgmtT, c
 	log.Infof("Requested set Ethernet interface %s", ethIfname)
 	setEthIntfCmd := cmd.NewSetEthIntfCmdT(changeItem.Change, this.ethSwitchMgmtClient)
 	if err := this.transConfigLookupTbl.checkDependenciesForSetEthIntf(ethIfname); err != nil {
-		return fmt.Errorf("Cannot %q because there are dependencies from Ethernet interface %s:\n%s",
-			setEthIntfCmd.GetName(), ethIfname, err)
+		return newValidationErr(ValidationReasonDependencyC, fmt.Errorf("Cannot %q because there are dependencies from Ethernet interface %s:\n%s",
+			setEthIntfCmd.GetName(), ethIfname, err))
 	}
 
 	if this.transHasBeenStarted {
@@ -80,8 +80,8 @@ func (this *ConfigMngrT) validateDeleteEthIntfChange(changeItem *DiffChangeMgmtT
 	log.Infof("Requested delete Ethernet interface %s", ethIfname)
 	deleteEthIntfCmd := cmd.NewDeleteEthIntfCmdT(changeItem.Change, this.ethSwitchMgmtClient)
 	if err := this.transConfigLookupTbl.checkDependenciesForDeleteEthIntf(ethIfname); err != nil {
-		return fmt.Errorf("Cannot %q because there are dependencies from Ethernet interface %s:\n%s",
-			deleteEthIntfCmd.GetName(), ethIfname, err)
+		return newValidationErr(ValidationReasonDependencyC, fmt.Errorf("Cannot %q because there are dependencies from Ethernet interface %s:\n%s",
+			deleteEthIntfCmd.GetName(), ethIfname, err))
 	}
 
 	if this.transHasBeenStarted {
@@ -140,7 +140,7 @@ func (this *ConfigMngrT) processSetEthIntfFromChangelog(changelog *DiffChangelog
 		// Repeat till there is not any change related to set Ethernet interface
 		if change, exists := findSetEthIntfChange(changelog); exists {
 			if err := this.validateSetEthIntfChange(change, changelog); err != nil {
-				return err
+				return newChangeValidationErr(change, err)
 			}
 		} else {
 			break
@@ -159,7 +159,7 @@ func (this *ConfigMngrT) processDeleteEthIntfFromChangelog(changelog *DiffChange
 		// Repeat till there is not any change related to delete Ethernet interface
 		if change, exists := findDeleteEthIntfChange(changelog); exists {
 			if err := this.validateDeleteEthIntfChange(change, changelog); err != nil {
-				return err
+				return newChangeValidationErr(change, err)
 			}
 		} else {
 			break
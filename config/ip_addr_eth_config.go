@@ -287,7 +287,7 @@ func (this *ConfigMngrT) validateSetIpv4AddrEthIntf(changeItem *DiffChangeMgmtT,
 	ifname := changeItem.Change.Path[cmd.Ipv4AddrEthIfnamePathItemIdxC]
 	if !this.isEthIntfAvailable(ifname) {
 		if !this.isEthIntfGoingToBeAvailableAfterPortBreakout(ifname) {
-			return fmt.Errorf("Ethernet interface %s is unrecognized", ifname)
+			return newValidationErr(ValidationReasonUnavailableC, fmt.Errorf("Ethernet interface %s is unrecognized", ifname))
 		}
 	}
 
@@ -331,8 +331,8 @@ func (this *ConfigMngrT) validateSetIpv4AddrEthIntf(changeItem *DiffChangeMgmtT,
 	log.Infof("Requested set IPv4 address %s for Ethernet interface %s", cidr, ifname)
 	setIpv4AddrEthIntfCmd := cmd.NewSetIpv4AddrEthIntfCmdT(ipChangeItem.Change, prfxLenChangeItem.Change, this.ethSwitchMgmtClient)
 	if err := this.transConfigLookupTbl.checkDependenciesForSetIpv4AddrForEthIntf(ifname, cidr); err != nil {
-		return fmt.Errorf("Cannot %q because there are dependencies from IPv4 address %s:\n%s",
-			setIpv4AddrEthIntfCmd.GetName(), cidr, err)
+		return newValidationErr(ValidationReasonDependencyC, fmt.Errorf("Cannot %q because there are dependencies from IPv4 address %s:\n%s",
+			setIpv4AddrEthIntfCmd.GetName(), cidr, err))
 	}
 
 	if this.transHasBeenStarted {
@@ -354,7 +354,7 @@ func (this *ConfigMngrT) validateSetIpv4AddrEthIntf(changeItem *DiffChangeMgmtT,
 func (this *ConfigMngrT) validateDeleteIpv4AddrEthIntf(changeItem *DiffChangeMgmtT, changelog *DiffChangelogMgmtT) error {
 	ifname := changeItem.Change.Path[cmd.Ipv4AddrEthIfnamePathItemIdxC]
 	if !this.isEthIntfAvailable(ifname) {
-		return fmt.Errorf("Ethernet interface %s is unrecognized", ifname)
+		return newValidationErr(ValidationReasonUnavailableC, fmt.Errorf("Ethernet interface %s is unrecognized", ifname))
 	}
 
 	var ipChangeItem *DiffChangeMgmtT
@@ -394,8 +394,8 @@ func (this *ConfigMngrT) validateDeleteIpv4AddrEthIntf(changeItem *DiffChangeMgm
 	log.Infof("Requested delete IPv4 address %s from Ethernet interface %s", cidr, ifname)
 	deleteIpv4AddrEthIntfCmd := cmd.NewDeleteIpv4AddrEthIntfCmdT(ipChangeItem.Change, prfxLenChangeItem.Change, this.ethSwitchMgmtClient)
 	if err := this.transConfigLookupTbl.checkDependenciesForDeleteIpv4AddrFromEthIntf(ifname, cidr); err != nil {
-		return fmt.Errorf("Cannot %q because there are dependencies from interface %s:\n%s",
-			deleteIpv4AddrEthIntfCmd.GetName(), ifname, err)
+		return newValidationErr(ValidationReasonDependencyC, fmt.Errorf("Cannot %q because there are dependencies from interface %s:\n%s",
+			deleteIpv4AddrEthIntfCmd.GetName(), ifname, err))
 	}
 
 	if this.transHasBeenStarted {
@@ -423,7 +423,7 @@ func (this *ConfigMngrT) processSetIpv4AddrEthIntfFromChangelog(changelog *DiffC
 		// Repeat till there is not any change related to delete IPv4 address from Ethernet interface
 		if change, exists := this.findSetIpv4AddrEthSubintfIp(changelog); exists {
 			if err := this.validateSetIpv4AddrEthIntf(change, changelog); err != nil {
-				return err
+				return newChangeValidationErr(change, err)
 			}
 		} else {
 			break
@@ -442,7 +442,7 @@ func (this *ConfigMngrT) processDeleteIpv4AddrEthIntfFromChangelog(changelog *Di
 		// Repeat till there is not any change related to delete IPv4 address from Ethernet interface
 		if change, exists := this.findDeleteIpv4AddrEthSubintfIp(changelog); exists {
 			if err := this.validateDeleteIpv4AddrEthIntf(change, changelog); err != nil {
-				return err
+				return newChangeValidationErr(change, err)
 			}
 		} else {
 			break
@@ -2,9 +2,19 @@ package config
 
 import (
 	"fmt"
+	cmd "opennos-mgmt/config/command"
 	"opennos-mgmt/gnmi/modeldata/oc"
+	"opennos-mgmt/utils"
+
+	log "github.com/golang/glog"
+	"github.com/r3labs/diff"
+)
+
+const (
+	lacpPathItemsCountC = 1
 )
 
+// isLacpDeleted checks if there is no LACP interface of aggregate interface in candidate config
 func (cfgMngr *ConfigMngrT) isLacpDeleted(ifname string) bool {
 	device := (*cfgMngr.transCandidateConfig).(*oc.Device)
 	lacpIntf, err := getLacpIntf(device, ifname)
@@ -29,3 +39,273 @@ func getLacpIntf(device *oc.Device, ifname string) (*oc.Lacp_Interface, error) {
 
 	return lacpIntf, nil
 }
+
+// checkLacpPairing checks if aggregate interface of LACP type has corresponding LACP interface and
+// aggregate interface of STATIC type has not
+func checkLacpPairing(device *oc.Device, aggIfname string) error {
+	_, err := getLacpIntf(device, aggIfname)
+	hasLacpIntf := err == nil
+	switch lagType := device.GetInterface(aggIfname).GetAggregation().GetLagType(); lagType {
+	case oc.OpenconfigIfAggregate_AggregationType_UNSET:
+		return newValidationErr(ValidationReasonInvalidChangeC, fmt.Errorf("LAG type for aggregate interface %s cannot be unset", aggIfname))
+	case oc.OpenconfigIfAggregate_AggregationType_LACP:
+		if !hasLacpIntf {
+			return newValidationErr(ValidationReasonDependencyC, fmt.Errorf("Aggregate interface %s of LACP type requires LACP interface to be created alongside",
+				aggIfname))
+		}
+	default:
+		if hasLacpIntf {
+			return newValidationErr(ValidationReasonDependencyC, fmt.Errorf("Aggregate interface %s of %s type cannot have LACP interface",
+				aggIfname, lagType.String()))
+		}
+	}
+
+	return nil
+}
+
+// checkLacpIntfsPairing checks if each LACP interface has corresponding aggregate interface of LACP type
+func checkLacpIntfsPairing(device *oc.Device) error {
+	lacp := device.GetLacp()
+	if lacp == nil {
+		return nil
+	}
+
+	for ifname := range lacp.Interface {
+		if err := checkLacpAggIntfLagType(device, ifname); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isCreateOrDeleteLacp checks if the whole LACP container is added to or removed from config
+func isCreateOrDeleteLacp(change *diff.Change) bool {
+	if len(change.Path) != lacpPathItemsCountC {
+		return false
+	}
+
+	return change.Path[cmd.LacpPathItemIdxC] == cmd.LacpPathItemC
+}
+
+func isChangedLacpIntfParam(change *diff.Change, paramIdx int, param string) bool {
+	if len(change.Path) != cmd.LacpPathItemsCountC {
+		return false
+	}
+
+	if change.Path[cmd.LacpPathItemIdxC] != cmd.LacpPathItemC {
+		return false
+	}
+
+	if change.Path[cmd.LacpInterfacePathItemIdxC] != cmd.LacpInterfacePathItemC {
+		return false
+	}
+
+	return change.Path[paramIdx] == param
+}
+
+func isChangedLacp(change *diff.Change) bool {
+	return isChangedLacpIntfParam(change, cmd.LacpNamePathItemIdxC, cmd.LacpNamePathItemC)
+}
+
+func createLacpIntfParamDiffChange(ifname string, paramIdx int, param string, value interface{}, isDelete bool) *diff.Change {
+	var ch diff.Change
+	if isDelete {
+		ch.Type = diff.DELETE
+		ch.From = value
+	} else {
+		ch.Type = diff.CREATE
+		ch.To = value
+	}
+	ch.Path = make([]string, cmd.LacpPathItemsCountC)
+	ch.Path[cmd.LacpPathItemIdxC] = cmd.LacpPathItemC
+	ch.Path[cmd.LacpInterfacePathItemIdxC] = cmd.LacpInterfacePathItemC
+	ch.Path[cmd.LacpIfnamePathItemIdxC] = ifname
+	ch.Path[paramIdx] = param
+
+	return &ch
+}
+
+// extractLacpParams extracts LACP interfaces, when the whole LACP container is added or removed,
+// because such change carries LACP container itself
+func extractLacpParams(changelog *diff.Changelog) (*diff.Changelog, error) {
+	changes := make([]diff.Change, 0)
+	for _, ch := range *changelog {
+		if !isCreateOrDeleteLacp(&ch) {
+			continue
+		}
+
+		isDelete := ch.To == nil
+		value := ch.To
+		if isDelete {
+			value = ch.From
+		}
+
+		lacp, ok := value.(*oc.Lacp)
+		if !ok {
+			return nil, fmt.Errorf("Unexpected type of LACP container: %T", value)
+		}
+
+		for ifname := range lacp.Interface {
+			changes = append(changes, *createLacpIntfParamDiffChange(ifname, cmd.LacpNamePathItemIdxC, cmd.LacpNamePathItemC, ifname, isDelete))
+		}
+	}
+
+	var newChangeLog diff.Changelog
+	newChangeLog = changes
+
+	return &newChangeLog, nil
+}
+
+// checkLacpAggIntfLagType checks if LACP can be configured for aggregate interface
+func checkLacpAggIntfLagType(device *oc.Device, ifname string) error {
+	lagType := device.GetInterface(ifname).GetAggregation().GetLagType()
+	switch lagType {
+	case oc.OpenconfigIfAggregate_AggregationType_LACP:
+		return nil
+	case oc.OpenconfigIfAggregate_AggregationType_UNSET:
+		return newValidationErr(ValidationReasonUnavailableC, fmt.Errorf("Aggregate interface %s does not exist", ifname))
+	}
+
+	return newValidationErr(ValidationReasonDependencyC, fmt.Errorf("Cannot configure LACP for aggregate interface %s of %s type",
+		ifname, lagType.String()))
+}
+
+func (cfgMngr *ConfigMngrT) validateSetLacpChange(changeItem *DiffChangeMgmtT, changelog *DiffChangelogMgmtT) error {
+	ifname, err := utils.ConvertGoInterfaceIntoString(changeItem.Change.To)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Requested enable LACP for aggregate interface %s", ifname)
+	device := (*cfgMngr.transCandidateConfig).(*oc.Device)
+	if err = checkLacpAggIntfLagType(device, ifname); err != nil {
+		return err
+	}
+
+	if cfgMngr.transHasBeenStarted {
+		setLacpCmd := cmd.NewSetLacpCmdT(changeItem.Change, cfgMngr.ethSwitchMgmtClient)
+		if err = cfgMngr.appendCmdToTransaction(ifname, setLacpCmd, setLacpC, false); err != nil {
+			return err
+		}
+	}
+
+	changeItem.MarkAsProcessed()
+
+	return nil
+}
+
+func (cfgMngr *ConfigMngrT) validateDeleteLacpChange(changeItem *DiffChangeMgmtT, changelog *DiffChangelogMgmtT) error {
+	ifname, err := utils.ConvertGoInterfaceIntoString(changeItem.Change.From)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Requested disable LACP for aggregate interface %s", ifname)
+	device := (*cfgMngr.transCandidateConfig).(*oc.Device)
+	if device.GetInterface(ifname).GetAggregation().GetLagType() == oc.OpenconfigIfAggregate_AggregationType_LACP {
+		return newValidationErr(ValidationReasonDependencyC, fmt.Errorf("Cannot disable LACP, which is required by aggregate interface %s of LACP type",
+			ifname))
+	}
+
+	if cfgMngr.transHasBeenStarted {
+		deleteLacpCmd := cmd.NewDeleteLacpCmdT(changeItem.Change, cfgMngr.ethSwitchMgmtClient)
+		if err = cfgMngr.appendCmdToTransaction(ifname, deleteLacpCmd, deleteLacpC, false); err != nil {
+			return err
+		}
+	}
+
+	changeItem.MarkAsProcessed()
+
+	return nil
+}
+
+// findSetLacpIntfParamChange returns change, which sets new value of LACP interface parameter
+func findSetLacpIntfParamChange(changelog *DiffChangelogMgmtT, isChanged func(change *diff.Change) bool) (change *DiffChangeMgmtT, exists bool) {
+	for _, ch := range changelog.Changes {
+		if !ch.IsProcessed() {
+			if ch.Change.Type != diff.DELETE {
+				if isChanged(ch.Change) {
+					if ch.Change.To != nil {
+						return ch, true
+					}
+				}
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// findDeleteLacpIntfParamChange returns change, which removes value of LACP interface parameter
+func findDeleteLacpIntfParamChange(changelog *DiffChangelogMgmtT, isChanged func(change *diff.Change) bool) (change *DiffChangeMgmtT, exists bool) {
+	for _, ch := range changelog.Changes {
+		if !ch.IsProcessed() {
+			if ch.Change.Type == diff.DELETE {
+				if isChanged(ch.Change) {
+					if ch.Change.From != nil {
+						return ch, true
+					}
+				}
+			}
+		}
+	}
+
+	return nil, false
+}
+
+func (cfgMngr *ConfigMngrT) processSetLacpFromChangelog(changelog *DiffChangelogMgmtT) error {
+	if changelog.isProcessed() {
+		return nil
+	}
+
+	for {
+		// Repeat till there is not any change related to enable LACP
+		if change, exists := findSetLacpIntfParamChange(changelog, isChangedLacp); exists {
+			if err := cfgMngr.validateSetLacpChange(change, changelog); err != nil {
+				return newChangeValidationErr(change, err)
+			}
+		} else {
+			break
+		}
+	}
+
+	return nil
+}
+
+func (cfgMngr *ConfigMngrT) processDeleteLacpFromChangelog(changelog *DiffChangelogMgmtT) error {
+	if changelog.isProcessed() {
+		return nil
+	}
+
+	for {
+		// Repeat till there is not any change related to disable LACP
+		if change, exists := findDeleteLacpIntfParamChange(changelog, isChangedLacp); exists {
+			if err := cfgMngr.validateDeleteLacpChange(change, changelog); err != nil {
+				return newChangeValidationErr(change, err)
+			}
+		} else {
+			break
+		}
+	}
+
+	return nil
+}
+
+// setLacp configures LACP of aggregate interfaces loaded from config
+func (cfgMngr *ConfigMngrT) setLacp(device *oc.Device) error {
+	lacp := device.GetLacp()
+	if lacp == nil {
+		return nil
+	}
+
+	for ifname := range lacp.Interface {
+		change := createLacpIntfParamDiffChange(ifname, cmd.LacpNamePathItemIdxC, cmd.LacpNamePathItemC, ifname, false)
+		setLacpCmd := cmd.NewSetLacpCmdT(change, cfgMngr.ethSwitchMgmtClient)
+		if err := cfgMngr.appendCmdToTransaction(ifname, setLacpCmd, setLacpC, false); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
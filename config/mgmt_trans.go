@@ -6,6 +6,7 @@ import (
 	"opennos-mgmt/gnmi/modeldata/oc"
 	"reflect"
 
+	"github.com/openconfig/ygot/ygot"
 	"github.com/r3labs/diff"
 )
 
@@ -15,6 +16,11 @@ const (
 	mgmtTransDefaultConfigActionPathItemIdxC  = 2
 	mgmtTransConfigActionPathItemIdxC         = 2
 	mgmtTransCommitConfirmTimeoutPathItemIdxC = 2
+	mgmtTransRollbackCommitIdPathItemIdxC     = 2
+	mgmtTransCommentPathItemIdxC              = 2
+	mgmtTransLabelPathItemIdxC                = 2
+	mgmtTransCommitAtPathItemIdxC             = 2
+	mgmtTransScheduledCommitIdPathItemIdxC    = 2
 	mgmtTransPathItemsCountC                  = 3
 
 	mgmtTransManagementPathItemC           = "Management"
@@ -22,6 +28,11 @@ const (
 	mgmtTransDefaultConfigActionPathItemC  = "DefaultConfigAction"
 	mgmtTransConfigActionPathItemC         = "ConfigAction"
 	mgmtTransCommitConfirmTimeoutPathItemC = "CommitConfirmTimeout"
+	mgmtTransRollbackCommitIdPathItemC     = "RollbackCommitId"
+	mgmtTransCommentPathItemC              = "Comment"
+	mgmtTransLabelPathItemC                = "Label"
+	mgmtTransCommitAtPathItemC             = "CommitAt"
+	mgmtTransScheduledCommitIdPathItemC    = "ScheduledCommitId"
 )
 
 func (cfgMngr *ConfigMngrT) getCurrentTransDefaultConfigAction() oc.E_OpenconfigManagement_TRANS_TYPE {
@@ -47,6 +58,23 @@ func checkMgmtTransParamIfItIsGoingToBeUnset(value interface{}) (bool, error) {
 func findDisallowedManagementTreeNodeDeleteOperation(changelog *DiffChangelogMgmtT) (*diff.Change, bool) {
 	for _, ch := range changelog.Changes {
 		if (len(ch.Change.Path) > 0) && (ch.Change.Path[mgmtTransManagementPathItemIdxC] == mgmtTransManagementPathItemC) {
+			// Commit to rollback is optional, the last one is taken if it is unset
+			if isMgmtTransRollbackCommitIdChange(ch.Change) {
+				continue
+			}
+
+			// Time of commit and scheduled commit to cancel are not kept in running config
+			if isMgmtTransParamChange(ch.Change, mgmtTransCommitAtPathItemIdxC, mgmtTransCommitAtPathItemC) ||
+				isMgmtTransParamChange(ch.Change, mgmtTransScheduledCommitIdPathItemIdxC, mgmtTransScheduledCommitIdPathItemC) {
+				continue
+			}
+
+			// Comment and label are cleared by device once changes are committed
+			if isMgmtTransParamChange(ch.Change, mgmtTransCommentPathItemIdxC, mgmtTransCommentPathItemC) ||
+				isMgmtTransParamChange(ch.Change, mgmtTransLabelPathItemIdxC, mgmtTransLabelPathItemC) {
+				continue
+			}
+
 			if ch.Change.Type != diff.CREATE {
 				if unset, err := checkMgmtTransParamIfItIsGoingToBeUnset(ch.Change.To); err != nil {
 					return nil, false
@@ -123,3 +151,183 @@ func (cfgMngr *ConfigMngrT) findTransCommitConfirmTimeoutChange(changelog *DiffC
 	device := cfgMngr.runningConfig.(*oc.Device)
 	return device.GetOrCreateManagement().GetOrCreateTransaction().GetCommitConfirmTimeout(), nil
 }
+
+func isMgmtTransParamChange(change *diff.Change, paramPathItemIdx int, paramPathItem string) bool {
+	if len(change.Path) != mgmtTransPathItemsCountC {
+		return false
+	}
+
+	return (change.Path[mgmtTransManagementPathItemIdxC] == mgmtTransManagementPathItemC) && (change.Path[mgmtTransTransactionPathItemIdxC] == mgmtTransTransactionPathItemC) && (change.Path[paramPathItemIdx] == paramPathItem)
+}
+
+func isMgmtTransRollbackCommitIdChange(change *diff.Change) bool {
+	return isMgmtTransParamChange(change, mgmtTransRollbackCommitIdPathItemIdxC, mgmtTransRollbackCommitIdPathItemC)
+}
+
+func (cfgMngr *ConfigMngrT) findTransUint32ParamChange(changelog *DiffChangelogMgmtT, paramPathItemIdx int, paramPathItem string) (*uint32, bool, error) {
+	// Find the latest one request of change this parameter
+	changed := false
+	var value *uint32
+	for _, ch := range changelog.Changes {
+		if isMgmtTransParamChange(ch.Change, paramPathItemIdx, paramPathItem) {
+			ch.MarkAsProcessed()
+			changed = true
+			switch v := ch.Change.To.(type) {
+			case uint32:
+				value = &v
+			case *uint32:
+				value = v
+			case nil:
+				value = nil
+			default:
+				return nil, false, fmt.Errorf("Cannot convert %v to uint32, unsupported type, got: %T", v, v)
+			}
+		}
+	}
+
+	return value, changed, nil
+}
+
+func (cfgMngr *ConfigMngrT) findTransRollbackCommitIdChange(changelog *DiffChangelogMgmtT) (*uint32, error) {
+	commitID, changed, err := cfgMngr.findTransUint32ParamChange(changelog, mgmtTransRollbackCommitIdPathItemIdxC, mgmtTransRollbackCommitIdPathItemC)
+	if err != nil || changed {
+		return commitID, err
+	}
+
+	device := cfgMngr.runningConfig.(*oc.Device)
+	return device.GetOrCreateManagement().GetOrCreateTransaction().RollbackCommitId, nil
+}
+
+func (cfgMngr *ConfigMngrT) findTransScheduledCommitIdChange(changelog *DiffChangelogMgmtT) (*uint32, error) {
+	commitID, _, err := cfgMngr.findTransUint32ParamChange(changelog, mgmtTransScheduledCommitIdPathItemIdxC, mgmtTransScheduledCommitIdPathItemC)
+	return commitID, err
+}
+
+func (cfgMngr *ConfigMngrT) findTransCommitAtChange(changelog *DiffChangelogMgmtT) (*uint64, error) {
+	// Find the latest one request of change this parameter
+	var commitAt *uint64
+	for _, ch := range changelog.Changes {
+		if isMgmtTransParamChange(ch.Change, mgmtTransCommitAtPathItemIdxC, mgmtTransCommitAtPathItemC) {
+			ch.MarkAsProcessed()
+			switch v := ch.Change.To.(type) {
+			case uint64:
+				commitAt = &v
+			case *uint64:
+				commitAt = v
+			case nil:
+				commitAt = nil
+			default:
+				return nil, fmt.Errorf("Cannot convert %v to uint64, unsupported type, got: %T", v, v)
+			}
+		}
+	}
+
+	return commitAt, nil
+}
+
+func (cfgMngr *ConfigMngrT) findTransStringParamChange(changelog *DiffChangelogMgmtT, paramPathItemIdx int, paramPathItem string) (string, error) {
+	// Find the latest one request of change this parameter
+	var value string
+	for _, ch := range changelog.Changes {
+		if isMgmtTransParamChange(ch.Change, paramPathItemIdx, paramPathItem) {
+			ch.MarkAsProcessed()
+			switch v := ch.Change.To.(type) {
+			case string:
+				value = v
+			case *string:
+				value = ""
+				if v != nil {
+					value = *v
+				}
+			case nil:
+				value = ""
+			default:
+				return "", fmt.Errorf("Cannot convert %v to string, unsupported type, got: %T", v, v)
+			}
+		}
+	}
+
+	return value, nil
+}
+
+func (cfgMngr *ConfigMngrT) findTransCommentChange(changelog *DiffChangelogMgmtT) (string, error) {
+	return cfgMngr.findTransStringParamChange(changelog, mgmtTransCommentPathItemIdxC, mgmtTransCommentPathItemC)
+}
+
+func (cfgMngr *ConfigMngrT) findTransLabelChange(changelog *DiffChangelogMgmtT) (string, error) {
+	return cfgMngr.findTransStringParamChange(changelog, mgmtTransLabelPathItemIdxC, mgmtTransLabelPathItemC)
+}
+
+// markTransParamChangesAsProcessed marks changes of all parameters of transaction as processed,
+// because they are not requested by client, but come along with the replayed config
+func (cfgMngr *ConfigMngrT) markTransParamChangesAsProcessed(changelog *DiffChangelogMgmtT) error {
+	if _, err := cfgMngr.findTransDefaultConfigActionChange(changelog); err != nil {
+		return err
+	}
+
+	if _, err := cfgMngr.findTransConfigActionChange(changelog); err != nil {
+		return err
+	}
+
+	if _, err := cfgMngr.findTransCommitConfirmTimeoutChange(changelog); err != nil {
+		return err
+	}
+
+	if _, err := cfgMngr.findTransRollbackCommitIdChange(changelog); err != nil {
+		return err
+	}
+
+	if _, err := cfgMngr.findTransCommitAtChange(changelog); err != nil {
+		return err
+	}
+
+	if _, err := cfgMngr.findTransScheduledCommitIdChange(changelog); err != nil {
+		return err
+	}
+
+	if _, err := cfgMngr.findTransCommentChange(changelog); err != nil {
+		return err
+	}
+
+	_, err := cfgMngr.findTransLabelChange(changelog)
+	return err
+}
+
+// transCommitDescFromChangelog returns comment and label of commit, which are set by changes of
+// changelog
+func transCommitDescFromChangelog(changelog diff.Changelog) (string, string) {
+	var comment, label string
+	for i := range changelog {
+		ch := &changelog[i]
+		if isMgmtTransParamChange(ch, mgmtTransCommentPathItemIdxC, mgmtTransCommentPathItemC) {
+			comment = transStringParamValue(ch.To)
+		} else if isMgmtTransParamChange(ch, mgmtTransLabelPathItemIdxC, mgmtTransLabelPathItemC) {
+			label = transStringParamValue(ch.To)
+		}
+	}
+
+	return comment, label
+}
+
+// clearTransCommitDesc clears comment and label of config, because they describe only the single
+// commit. The next commit gets them from its own changes, even if they are the same.
+func clearTransCommitDesc(config ygot.ValidatedGoStruct) {
+	trans := config.(*oc.Device).GetManagement().GetTransaction()
+	if trans != nil {
+		trans.Comment = nil
+		trans.Label = nil
+	}
+}
+
+func transStringParamValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case *string:
+		if v != nil {
+			return *v
+		}
+	}
+
+	return ""
+}
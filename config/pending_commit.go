@@ -0,0 +1,153 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"opennos-mgmt/gnmi"
+	"os"
+	"time"
+
+	log "github.com/golang/glog"
+	"github.com/openconfig/ygot/ygot"
+)
+
+const (
+	pendingCommitFilenameC = "pending-commit.json"
+)
+
+// pendingCommitT is commit awaiting confirmation, which is persisted to survive restart
+type pendingCommitT struct {
+	ID             string          `json:"id"`
+	Deadline       time.Time       `json:"deadline"`
+	RollbackConfig json.RawMessage `json:"rollback-config"` // Config before commit
+	CommitConfig   json.RawMessage `json:"commit-config"`   // Config awaiting confirmation
+}
+
+// resumedCommitT is commit awaiting confirmation, which has been restored after restart.
+// Its transaction is gone, so changes are withdrawn by restoring config before commit.
+type resumedCommitT struct {
+	id             string
+	rollbackConfig ygot.ValidatedGoStruct
+}
+
+func savePendingCommit(id string, deadline time.Time, rollbackConfig ygot.ValidatedGoStruct, commitConfig ygot.ValidatedGoStruct) error {
+	rawRollbackConfig, err := gnmi.ConvertYgotGoStructIntoJsonByteStream(rollbackConfig)
+	if err != nil {
+		return err
+	}
+
+	rawCommitConfig, err := gnmi.ConvertYgotGoStructIntoJsonByteStream(commitConfig)
+	if err != nil {
+		return err
+	}
+
+	jsonDump, err := json.MarshalIndent(&pendingCommitT{
+		ID:             id,
+		Deadline:       deadline,
+		RollbackConfig: rawRollbackConfig,
+		CommitConfig:   rawCommitConfig,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(pendingCommitFilenameC, jsonDump, 0644)
+}
+
+// loadPendingCommit returns commit awaiting confirmation before restart, or nil if there is no such one
+func loadPendingCommit() (*pendingCommitT, error) {
+	jsonDump, err := ioutil.ReadFile(pendingCommitFilenameC)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	var pending pendingCommitT
+	if err = json.Unmarshal(jsonDump, &pending); err != nil {
+		return nil, fmt.Errorf("Failed to parse pending commit from %s: %s", pendingCommitFilenameC, err)
+	}
+
+	return &pending, nil
+}
+
+func removePendingCommit() {
+	if err := os.Remove(pendingCommitFilenameC); err != nil && !os.IsNotExist(err) {
+		log.Errorf("Failed to remove pending commit: %s", err)
+	}
+}
+
+// persistPendingCommit saves commit awaiting confirmation, so it can be withdrawn after restart
+func (this *ConfigMngrT) persistPendingCommit(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	if this.resumedCommit != nil {
+		return savePendingCommit(this.resumedCommit.id, deadline, this.resumedCommit.rollbackConfig, this.runningConfig)
+	}
+
+	return savePendingCommit(this.transCommitID, deadline, this.runningConfig, *this.transCandidateConfig)
+}
+
+// resumePendingCommit restores commit awaiting confirmation before restart. Device keeps its
+// config, so it becomes running config. Changes are withdrawn immediately if timeout has expired.
+func (this *ConfigMngrT) resumePendingCommit(model *gnmi.Model, pending *pendingCommitT, commitConfig ygot.ValidatedGoStruct) error {
+	rollbackConfig, err := model.NewConfigStruct(pending.RollbackConfig)
+	if err != nil {
+		return fmt.Errorf("Failed to load config before pending commit: %s", err)
+	}
+
+	this.runningConfig = commitConfig
+	this.transCommitID = pending.ID
+	this.resumedCommit = &resumedCommitT{
+		id:             pending.ID,
+		rollbackConfig: rollbackConfig,
+	}
+
+	timeout := time.Until(pending.Deadline)
+	if timeout <= 0 {
+		log.Infof("Confirmation timeout of commit %q has expired during restart, rollback changes", pending.ID)
+		return this.rollbackResumedCommit()
+	}
+
+	log.Infof("Resumed commit %q awaiting confirmation in %s", pending.ID, timeout)
+	this.startConfirmationTimeout(timeout)
+	return nil
+}
+
+func (this *ConfigMngrT) getResumedCommit() (string, ygot.ValidatedGoStruct, ygot.ValidatedGoStruct, bool) {
+	if this.resumedCommit == nil {
+		return "", nil, nil, false
+	}
+
+	return this.resumedCommit.id, this.runningConfig, this.resumedCommit.rollbackConfig, true
+}
+
+func (this *ConfigMngrT) confirmResumedCommit() error {
+	resumedCommit := this.resumedCommit
+	candidateConfig := this.runningConfig
+	this.resumedCommit = nil
+	this.transCommitID = ""
+	this.transConfirmationTimeoutCtx = nil
+	this.transConfirmationCancel = nil
+	if err := this.commitCandidateConfig(&candidateConfig); err != nil {
+		return err
+	}
+
+	this.prevRunningConfig = resumedCommit.rollbackConfig
+	removePendingCommit()
+	return nil
+}
+
+func (this *ConfigMngrT) rollbackResumedCommit() error {
+	resumedCommit := this.resumedCommit
+	this.resumedCommit = nil
+	if err := this.rollbackToConfig(resumedCommit.rollbackConfig); err != nil {
+		this.resumedCommit = resumedCommit
+		return err
+	}
+
+	removePendingCommit()
+	return nil
+}
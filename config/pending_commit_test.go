@@ -0,0 +1,106 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/openconfig/ygot/ygot"
+
+	"opennos-mgmt/gnmi"
+	"opennos-mgmt/gnmi/modeldata"
+	"opennos-mgmt/gnmi/modeldata/oc"
+)
+
+func TestRestartWithPendingCommit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "opennos-mgmt")
+	if err != nil {
+		t.Fatalf("error in creating temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("error in getting working directory: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("error in changing working directory: %v", err)
+	}
+	defer os.Chdir(wd)
+
+	model := gnmi.NewModel(modeldata.ModelData,
+		reflect.TypeOf((*oc.Device)(nil)),
+		oc.SchemaTree["Device"],
+		oc.Unmarshal,
+		oc.ΛEnum)
+	startupConfig := []byte(`{"interfaces": {"interface": [{"name": "eth-1", "config": {"name": "eth-1", "description": "uplink"}}]}}`)
+	rollbackConfig, err := model.NewConfigStruct(startupConfig)
+	if err != nil {
+		t.Fatalf("error in creating config struct: %v", err)
+	}
+	commitConfig, err := model.NewConfigStruct([]byte(`{"interfaces": {"interface": [{"name": "eth-1", "config": {"name": "eth-1", "description": "downlink"}}]}}`))
+	if err != nil {
+		t.Fatalf("error in creating config struct: %v", err)
+	}
+	if err := savePendingCommit("commit-1", time.Now().Add(time.Hour), rollbackConfig, commitConfig); err != nil {
+		t.Fatalf("error in saving pending commit: %v", err)
+	}
+
+	// Restart finds pending-commit.json and resumes counting for confirmation
+	configMngr := NewConfigMngrT()
+	pending, err := loadPendingCommit()
+	if err != nil || pending == nil {
+		t.Fatalf("loadPendingCommit() = %v, %v, want pending commit", pending, err)
+	}
+	err = configMngr.doInCommitWorker(func() error {
+		pendingConfig, err := model.NewConfigStruct(pending.CommitConfig)
+		if err != nil {
+			return err
+		}
+		return configMngr.resumePendingCommit(model, pending, pendingConfig)
+	})
+	if err != nil {
+		t.Fatalf("error in resuming pending commit: %v", err)
+	}
+	defer configMngr.doInCommitWorker(func() error {
+		configMngr.transConfirmationCancel()
+		return nil
+	})
+
+	// The same as callback of main, every change of config has to be committed
+	callback := func(newConfig ygot.ValidatedGoStruct, cbUserData interface{}) (*gnmi.ApplyResult, error) {
+		changelog, err := configMngr.GetDiffRunningConfigWithCandidateConfig(&newConfig)
+		if err != nil {
+			return nil, err
+		}
+		if len(changelog) == 0 {
+			return nil, nil
+		}
+		return configMngr.CommitChangelog(&changelog, &newConfig)
+	}
+
+	if _, err := gnmi.NewServer(model, startupConfig, callback, nil); err == nil {
+		t.Errorf("NewServer with startup config succeeded, want error of commit awaiting confirmation")
+	}
+
+	runningConfig, err := configMngr.GetRunningConfig()
+	if err != nil {
+		t.Fatalf("error in getting running config: %v", err)
+	}
+	s, err := gnmi.NewServer(model, runningConfig, callback, nil)
+	if err != nil {
+		t.Fatalf("error in creating server with running config: %v", err)
+	}
+
+	id, gotConfig, gotRollbackConfig, resumed := configMngr.GetResumedCommit()
+	if !resumed || id != "commit-1" {
+		t.Fatalf("GetResumedCommit() = %q, %v, want %q, true", id, resumed, "commit-1")
+	}
+	if got := gotConfig.(*oc.Device).GetInterface("eth-1").GetDescription(); got != "downlink" {
+		t.Errorf("description of eth-1 in running config = %q, want %q", got, "downlink")
+	}
+	if err := s.ResumePendingCommit(id, gotConfig, gotRollbackConfig); err != nil {
+		t.Errorf("error in resuming pending commit by server: %v", err)
+	}
+}
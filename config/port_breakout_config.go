@@ -212,7 +212,7 @@ func (this *ConfigMngrT) validatePortBreakoutChange(changedItem *DiffChangeMgmtT
 	}
 
 	if device.GetComponent(ifname) == nil {
-		return fmt.Errorf("Cannot breakout port for Ethernet interface %s because component does not exist", ifname)
+		return newValidationErr(ValidationReasonUnavailableC, fmt.Errorf("Cannot breakout port for Ethernet interface %s because component does not exist", ifname))
 	}
 
 	// if !this.isEthIntfAvailable(ifname) {
@@ -274,8 +274,8 @@ func (this *ConfigMngrT) validatePortBreakoutChange(changedItem *DiffChangeMgmtT
 		}
 
 		if errMsg.Len() > 0 {
-			return fmt.Errorf("Cannot %q because there are dependencies:\n%s",
-				setPortBreakoutCmd.GetName(), errMsg.String())
+			return newValidationErr(ValidationReasonDependencyC, fmt.Errorf("Cannot %q because there are dependencies:\n%s",
+				setPortBreakoutCmd.GetName(), errMsg.String()))
 		}
 	} else {
 		if err := this.transConfigLookupTbl.checkDependenciesForDeletePortBreakout(ifname); err != nil {
@@ -334,7 +334,7 @@ func (this *ConfigMngrT) processSetPortBreakoutFromChangelog(changelog *DiffChan
 		// Repeat till there is not any change related to set port breakout for port
 		if change, exists := this.findSetPortBreakout(changelog); exists {
 			if err := this.validatePortBreakoutChange(change, changelog); err != nil {
-				return err
+				return newChangeValidationErr(change, err)
 			}
 		} else {
 			break
@@ -353,7 +353,7 @@ func (this *ConfigMngrT) processSetPortBreakoutChanSpeedFromChangelog(changelog
 		// Repeat till there is not any change related to set port breakout channel speed for subports
 		if change, exists := this.findSetPortBreakoutChanSpeed(changelog); exists {
 			if err := this.validatePortBreakoutChannSpeedChange(change, changelog); err != nil {
-				return err
+				return newChangeValidationErr(change, err)
 			}
 		} else {
 			break
@@ -0,0 +1,263 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"opennos-mgmt/gnmi/modeldata/oc"
+	"time"
+
+	log "github.com/golang/glog"
+	"github.com/openconfig/ygot/ygot"
+	"github.com/r3labs/diff"
+)
+
+const (
+	maxScheduledCommitsC = 16 // The number of commits, which can await their time at once
+
+	mgmtScheduledCommitPathItemIdxC = 1
+	mgmtScheduledCommitPathItemC    = "ScheduledCommit"
+)
+
+// scheduledCommitT describes validated changes of config, which are going to be committed at
+// given time. Scheduled commits are kept in memory only, so they are lost on restart.
+type scheduledCommitT struct {
+	id        uint32
+	commitAt  time.Time
+	session   SessionT
+	comment   string
+	label     string
+	changelog diff.Changelog // Changes of config requested by user, without parameters of transaction
+	cancel    context.CancelFunc
+}
+
+// scheduleCommit validates changes against running config and keeps them until the time of
+// commit. Candidate config is reset to running config, because the changes are not applied yet.
+func (this *ConfigMngrT) scheduleCommit(changelog *diff.Changelog, diffChangelog *DiffChangelogMgmtT,
+	candidateConfig *ygot.ValidatedGoStruct, commitAt time.Time) error {
+	if !commitAt.After(time.Now()) {
+		return newValidationErr(ValidationReasonUnsupportedC, fmt.Errorf("Time of commit %s is in the past", commitAt))
+	}
+
+	if len(this.scheduledCommits) >= maxScheduledCommitsC {
+		return newValidationErr(ValidationReasonUnavailableC, fmt.Errorf("There are already %d scheduled commits", len(this.scheduledCommits)))
+	}
+
+	configChangelog := make(diff.Changelog, 0, len(*changelog))
+	for i := 0; i < len(*changelog); i++ {
+		path := (*changelog)[i].Path
+		if (len(path) > 0) && (path[mgmtTransManagementPathItemIdxC] == mgmtTransManagementPathItemC) {
+			continue
+		}
+
+		configChangelog = append(configChangelog, (*changelog)[i])
+	}
+
+	if len(configChangelog) == 0 {
+		return newValidationErr(ValidationReasonUnavailableC, errors.New("There are no changes of config to schedule"))
+	}
+
+	if _, err := this.commitDiffChangelog(diffChangelog, candidateConfig, oc.OpenconfigManagement_TRANS_TYPE_TRANS_DRY_RUN, 0); err != nil {
+		return err
+	}
+
+	comment, label := transCommitDescFromChangelog(*changelog)
+	this.lastScheduledCommit++
+	ctx, cancel := context.WithCancel(context.Background())
+	scheduledCommit := &scheduledCommitT{
+		id:        this.lastScheduledCommit,
+		commitAt:  commitAt,
+		session:   this.transSession,
+		comment:   comment,
+		label:     label,
+		changelog: configChangelog,
+		cancel:    cancel,
+	}
+	this.scheduledCommits[scheduledCommit.id] = scheduledCommit
+	go this.waitForScheduledCommit(ctx, scheduledCommit.id, commitAt)
+
+	log.Infof("Scheduled commit %d of session %s with %d changes at %s", scheduledCommit.id, this.transSession,
+		len(configChangelog), commitAt)
+	return this.updateScheduledCommits(candidateConfig)
+}
+
+// cancelScheduledCommit withdraws commit, which has not been applied yet
+func (this *ConfigMngrT) cancelScheduledCommit(id uint32, candidateConfig *ygot.ValidatedGoStruct) error {
+	scheduledCommit, exists := this.scheduledCommits[id]
+	if !exists {
+		return newValidationErr(ValidationReasonUnavailableC, fmt.Errorf("There is no scheduled commit %d", id))
+	}
+
+	scheduledCommit.cancel()
+	delete(this.scheduledCommits, id)
+	log.Infof("Cancelled scheduled commit %d by session %s", id, this.transSession)
+	return this.updateScheduledCommits(candidateConfig)
+}
+
+func (this *ConfigMngrT) waitForScheduledCommit(ctx context.Context, id uint32, commitAt time.Time) {
+	select {
+	case <-time.After(time.Until(commitAt)):
+		this.doInCommitWorker(func() error {
+			// Commit could be cancelled while it has been queued
+			if ctx.Err() != nil {
+				log.Infof("Cancelled waiting for scheduled commit %d", id)
+				return nil
+			}
+
+			return this.runScheduledCommit(id)
+		})
+	case <-ctx.Done():
+		log.Infof("Cancelled waiting for scheduled commit %d", id)
+	}
+}
+
+// runScheduledCommit applies scheduled changes onto the current running config, validates them
+// again and commits them
+func (this *ConfigMngrT) runScheduledCommit(id uint32) error {
+	scheduledCommit := this.scheduledCommits[id]
+	delete(this.scheduledCommits, id)
+	defer this.notifyRunningConfig()
+
+	if err := this.commitScheduledChangelog(scheduledCommit); err != nil {
+		log.Errorf("Failed to run scheduled commit %d: %s", id, err)
+		// On success list of scheduled commits is updated along with the commit
+		if updateErr := this.updateScheduledCommits(nil); updateErr != nil {
+			log.Errorf("Failed to update scheduled commits: %s", updateErr)
+		}
+		return err
+	}
+
+	log.Infof("Applied scheduled commit %d of session %s", id, scheduledCommit.session)
+	return nil
+}
+
+func (this *ConfigMngrT) commitScheduledChangelog(scheduledCommit *scheduledCommitT) error {
+	if this.isCommitAwaitingConfirmation() {
+		return errors.New("Cannot run scheduled commit while there are changes awaiting confirmation")
+	}
+
+	configCopy, err := ygot.DeepCopy(this.runningConfig)
+	if err != nil {
+		return err
+	}
+
+	// Changes committed in the meantime are kept, unless they touch nodes changed by scheduled commit
+	if err = applyChangelog(scheduledCommit.changelog, configCopy); err != nil {
+		return newValidationErr(ValidationReasonDependencyC, fmt.Errorf("Running config has changed since commit %d was scheduled: %s",
+			scheduledCommit.id, err))
+	}
+
+	// Scheduled changes share nodes with changelog, so they are copied again to be owned by config
+	if configCopy, err = ygot.DeepCopy(configCopy); err != nil {
+		return err
+	}
+
+	device := configCopy.(*oc.Device)
+	trans := device.GetOrCreateManagement().GetOrCreateTransaction()
+	trans.Comment = nil
+	trans.Label = nil
+	if scheduledCommit.comment != "" {
+		trans.Comment = ygot.String(scheduledCommit.comment)
+	}
+	if scheduledCommit.label != "" {
+		trans.Label = ygot.String(scheduledCommit.label)
+	}
+
+	if err = device.Validate(); err != nil {
+		return newValidationErr(ValidationReasonInvalidChangeC, fmt.Errorf("Scheduled commit %d is invalid on running config: %s",
+			scheduledCommit.id, err))
+	}
+
+	config := ygot.ValidatedGoStruct(device)
+	changelog, err := this.diffRunningConfigWithCandidateConfig(&config)
+	if err != nil {
+		return err
+	}
+
+	diffChangelog, err := this.extendChangelog(&changelog)
+	if err != nil {
+		return err
+	}
+
+	if err = this.markTransParamChangesAsProcessed(diffChangelog); err != nil {
+		return err
+	}
+
+	// Commit is recorded as requested by session, which has scheduled it
+	transSession := this.transSession
+	this.transSession = scheduledCommit.session
+	defer func() { this.transSession = transSession }()
+
+	// Config could be locked by other user since commit has been scheduled
+	if err = this.checkConfigLock(); err != nil {
+		return err
+	}
+
+	_, err = this.commitDiffChangelog(diffChangelog, &config, oc.OpenconfigManagement_TRANS_TYPE_TRANS_COMMIT, 0)
+	return err
+}
+
+// updateScheduledCommits exposes scheduled commits in running config. Candidate config, if
+// given, is reset to running config.
+func (this *ConfigMngrT) updateScheduledCommits(candidateConfig *ygot.ValidatedGoStruct) error {
+	scheduledCommits, err := this.scheduledCommitsToOc()
+	if err != nil {
+		return err
+	}
+
+	this.runningConfig.(*oc.Device).GetOrCreateManagement().ScheduledCommit = scheduledCommits
+	if candidateConfig == nil {
+		return nil
+	}
+
+	runningConfig, err := ygot.DeepCopy(this.runningConfig)
+	if err != nil {
+		return err
+	}
+
+	*(*candidateConfig).(*oc.Device) = *runningConfig.(*oc.Device)
+	return nil
+}
+
+// scheduledCommitsToOc converts scheduled commits into list of scheduled commits of management model
+func (this *ConfigMngrT) scheduledCommitsToOc() (map[uint32]*oc.Management_ScheduledCommit, error) {
+	scheduledCommits := make(map[uint32]*oc.Management_ScheduledCommit, len(this.scheduledCommits))
+	for _, scheduledCommit := range this.scheduledCommits {
+		jsonDump, err := json.Marshal(scheduledCommit.changelog)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to JSON dump changelog of scheduled commit %d: %s", scheduledCommit.id, err)
+		}
+
+		id := scheduledCommit.id
+		commitAt := uint64(scheduledCommit.commitAt.UnixNano())
+		user := scheduledCommit.session.User
+		changelog := string(jsonDump)
+		commit := &oc.Management_ScheduledCommit{
+			Id:        &id,
+			CommitAt:  &commitAt,
+			User:      &user,
+			Changelog: &changelog,
+		}
+		if scheduledCommit.comment != "" {
+			comment := scheduledCommit.comment
+			commit.Comment = &comment
+		}
+		if scheduledCommit.label != "" {
+			label := scheduledCommit.label
+			commit.Label = &label
+		}
+		scheduledCommits[id] = commit
+	}
+
+	return scheduledCommits, nil
+}
+
+func isScheduledCommitsChange(change *diff.Change) bool {
+	if len(change.Path) <= mgmtScheduledCommitPathItemIdxC {
+		return false
+	}
+
+	return (change.Path[mgmtTransManagementPathItemIdxC] == mgmtTransManagementPathItemC) &&
+		(change.Path[mgmtScheduledCommitPathItemIdxC] == mgmtScheduledCommitPathItemC)
+}
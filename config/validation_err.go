@@ -0,0 +1,53 @@
+package config
+
+import (
+	"opennos-mgmt/gnmi"
+)
+
+// ValidationReasonT is the reason code of rejecting change of config
+type ValidationReasonT string
+
+// The following constants define reason codes of rejecting change of config
+const (
+	ValidationReasonInvalidChangeC ValidationReasonT = "INVALID_CHANGE" // Change is invalid for other reason
+	ValidationReasonDependencyC    ValidationReasonT = "DEPENDENCY"     // Change breaks dependencies between config nodes
+	ValidationReasonUnavailableC   ValidationReasonT = "UNAVAILABLE"    // Change refers to node which does not exist or is not available
+	ValidationReasonUnsupportedC   ValidationReasonT = "UNSUPPORTED"    // Requested transition of config is not supported
+)
+
+// validationErrT carries the reason code of validation error
+type validationErrT struct {
+	reason ValidationReasonT
+	err    error
+}
+
+func newValidationErr(reason ValidationReasonT, err error) *validationErrT {
+	return &validationErrT{
+		reason: reason,
+		err:    err,
+	}
+}
+
+func (this *validationErrT) Error() string {
+	return this.err.Error()
+}
+
+// newChangeValidationErr binds validation error with the change of config rejected by it
+func newChangeValidationErr(changeItem *DiffChangeMgmtT, err error) error {
+	switch e := err.(type) {
+	case *gnmi.ChangeError:
+		return e
+	case *validationErrT:
+		return &gnmi.ChangeError{
+			Path:   changeItem.Change.Path,
+			Reason: string(e.reason),
+			Err:    e.err,
+		}
+	default:
+		return &gnmi.ChangeError{
+			Path:   changeItem.Change.Path,
+			Reason: string(ValidationReasonInvalidChangeC),
+			Err:    err,
+		}
+	}
+}
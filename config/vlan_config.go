@@ -353,15 +353,15 @@ func findDeleteTrunkVlanEthIntfChange(changelog *DiffChangelogMgmtT) (change *Di
 func (this *ConfigMngrT) validateSetVlanModeEthIntfChange(changeItem *DiffChangeMgmtT, changelog *DiffChangelogMgmtT) error {
 	ifname := changeItem.Change.Path[cmd.VlanEthIfnamePathItemIdxC]
 	if !this.isEthIntfAvailable(ifname) {
-		return fmt.Errorf("Ethernet interface %s is not available", ifname)
+		return newValidationErr(ValidationReasonUnavailableC, fmt.Errorf("Ethernet interface %s is not available", ifname))
 	}
 
 	vlanMode := changeItem.Change.To.(oc.E_OpenconfigVlan_VlanModeType)
 	log.Infof("Requested set VLAN mode (%d) for Ethernet interface %s", vlanMode, ifname)
 	setVlanModeEthIntfCmd := cmd.NewSetVlanModeEthIntfCmdT(changeItem.Change, this.ethSwitchMgmtClient)
 	if err := this.transConfigLookupTbl.checkDependenciesForSetVlanModeForEthIntf(ifname, vlanMode); err != nil {
-		return fmt.Errorf("Cannot %q because there are dependencies from interface %s:\n%s",
-			setVlanModeEthIntfCmd.GetName(), ifname, err)
+		return newValidationErr(ValidationReasonDependencyC, fmt.Errorf("Cannot %q because there are dependencies from interface %s:\n%s",
+			setVlanModeEthIntfCmd.GetName(), ifname, err))
 	}
 
 	if this.transHasBeenStarted {
@@ -382,7 +382,7 @@ func (this *ConfigMngrT) validateSetVlanModeEthIntfChange(changeItem *DiffChange
 func (this *ConfigMngrT) validateSetAccessVlanEthIntfChange(changeItem *DiffChangeMgmtT, changelog *DiffChangelogMgmtT) error {
 	ifname := changeItem.Change.Path[cmd.VlanEthIfnamePathItemIdxC]
 	if !this.isEthIntfAvailable(ifname) {
-		return fmt.Errorf("Ethernet interface %s is not available", ifname)
+		return newValidationErr(ValidationReasonUnavailableC, fmt.Errorf("Ethernet interface %s is not available", ifname))
 	}
 
 	vid16, err := utils.ConvertGoInterfaceIntoUint16(changeItem.Change.To)
@@ -395,7 +395,7 @@ func (this *ConfigMngrT) validateSetAccessVlanEthIntfChange(changeItem *DiffChan
 	if exists {
 		reqVlanMode := oc.E_OpenconfigVlan_VlanModeType(*vlanModeChange.Change.To.(*uint8))
 		if reqVlanMode != oc.OpenconfigVlan_VlanModeType_TRUNK {
-			return fmt.Errorf("Set access VLAN %d for Ethernet interface %s is disallowed if VLAN interface mode is not going to be access.\nRequested mode: %v", vid, ifname, reqVlanMode)
+			return newValidationErr(ValidationReasonUnsupportedC, fmt.Errorf("Set access VLAN %d for Ethernet interface %s is disallowed if VLAN interface mode is not going to be access.\nRequested mode: %v", vid, ifname, reqVlanMode))
 		}
 	} else {
 		vlanMode, err := this.transConfigLookupTbl.getVlanModeEthIntf(ifname)
@@ -404,15 +404,15 @@ func (this *ConfigMngrT) validateSetAccessVlanEthIntfChange(changeItem *DiffChan
 		}
 
 		if vlanMode != oc.OpenconfigVlan_VlanModeType_ACCESS {
-			return fmt.Errorf("Set access VLAN %d for Ethernet interface %s is disallowed if VLAN interface mode is not access. Current mode: %v", vid, ifname, vlanMode)
+			return newValidationErr(ValidationReasonUnsupportedC, fmt.Errorf("Set access VLAN %d for Ethernet interface %s is disallowed if VLAN interface mode is not access. Current mode: %v", vid, ifname, vlanMode))
 		}
 	}
 
 	log.Infof("Requested set access VLAN %d for Ethernet interface %s", vid, ifname)
 	setAccessVlanEthIntfCmd := cmd.NewSetAccessVlanEthIntfCmdT(changeItem.Change, this.ethSwitchMgmtClient)
 	if err := this.transConfigLookupTbl.checkDependenciesForSetAccessVlanForEthIntf(ifname, vid); err != nil {
-		return fmt.Errorf("Cannot %q because there are dependencies from interface %s:\n%s",
-			setAccessVlanEthIntfCmd.GetName(), ifname, err)
+		return newValidationErr(ValidationReasonDependencyC, fmt.Errorf("Cannot %q because there are dependencies from interface %s:\n%s",
+			setAccessVlanEthIntfCmd.GetName(), ifname, err))
 	}
 
 	if this.transHasBeenStarted {
@@ -443,7 +443,7 @@ func (this *ConfigMngrT) validateSetAccessVlanEthIntfChange(changeItem *DiffChan
 func (this *ConfigMngrT) validateDeleteAccessVlanEthIntfChange(changeItem *DiffChangeMgmtT, changelog *DiffChangelogMgmtT) error {
 	ifname := changeItem.Change.Path[cmd.VlanEthIfnamePathItemIdxC]
 	if !this.isEthIntfAvailable(ifname) {
-		return fmt.Errorf("Ethernet interface %s is not available", ifname)
+		return newValidationErr(ValidationReasonUnavailableC, fmt.Errorf("Ethernet interface %s is not available", ifname))
 	}
 
 	vid16, err := utils.ConvertGoInterfaceIntoUint16(changeItem.Change.From)
@@ -454,7 +454,7 @@ func (this *ConfigMngrT) validateDeleteAccessVlanEthIntfChange(changeItem *DiffC
 	vid := lib.VidT(vid16)
 	vlanMode, err := this.transConfigLookupTbl.getVlanModeEthIntf(ifname)
 	if vlanMode != oc.OpenconfigVlan_VlanModeType_ACCESS {
-		return fmt.Errorf("Deletion of access VLAN %d from Ethernet interface %s is disallowed if VLAN interface mode is not access. Current mode: %v", vid, ifname, vlanMode)
+		return newValidationErr(ValidationReasonUnsupportedC, fmt.Errorf("Deletion of access VLAN %d from Ethernet interface %s is disallowed if VLAN interface mode is not access. Current mode: %v", vid, ifname, vlanMode))
 	}
 
 	var newChange diff.Change
@@ -473,8 +473,8 @@ func (this *ConfigMngrT) validateDeleteAccessVlanEthIntfChange(changeItem *DiffC
 	log.Infof("Requested delete access VLAN %d from Ethernet interface %s", vid, ifname)
 	deleteAccessVlanEthIntfCmd := cmd.NewDeleteAccessVlanEthIntfCmdT(changeItem.Change, this.ethSwitchMgmtClient)
 	if err := this.transConfigLookupTbl.checkDependenciesForDeleteAccessVlanFromEthIntf(ifname, vid); err != nil {
-		return fmt.Errorf("Cannot %q because there are dependencies from interface %s:\n%s",
-			deleteAccessVlanEthIntfCmd.GetName(), ifname, err)
+		return newValidationErr(ValidationReasonDependencyC, fmt.Errorf("Cannot %q because there are dependencies from interface %s:\n%s",
+			deleteAccessVlanEthIntfCmd.GetName(), ifname, err))
 	}
 
 	if this.transHasBeenStarted {
@@ -512,7 +512,7 @@ func (this *ConfigMngrT) validateDeleteAccessVlanEthIntfChange(changeItem *DiffC
 func (this *ConfigMngrT) validateSetNativeVlanEthIntfChange(changeItem *DiffChangeMgmtT, changelog *DiffChangelogMgmtT) error {
 	ifname := changeItem.Change.Path[cmd.VlanEthIfnamePathItemIdxC]
 	if !this.isEthIntfAvailable(ifname) {
-		return fmt.Errorf("Ethernet interface %s is not available", ifname)
+		return newValidationErr(ValidationReasonUnavailableC, fmt.Errorf("Ethernet interface %s is not available", ifname))
 	}
 
 	vid16, err := utils.ConvertGoInterfaceIntoUint16(changeItem.Change.To)
@@ -525,7 +525,7 @@ func (this *ConfigMngrT) validateSetNativeVlanEthIntfChange(changeItem *DiffChan
 	if exists {
 		reqVlanMode := oc.E_OpenconfigVlan_VlanModeType(*vlanModeChange.Change.To.(*uint8))
 		if reqVlanMode != oc.OpenconfigVlan_VlanModeType_TRUNK {
-			return fmt.Errorf("Set native VLAN %d for Ethernet interface %s is disallowed if VLAN interface mode is not going to be trunk.\nRequested mode: %v", vid, ifname, reqVlanMode)
+			return newValidationErr(ValidationReasonUnsupportedC, fmt.Errorf("Set native VLAN %d for Ethernet interface %s is disallowed if VLAN interface mode is not going to be trunk.\nRequested mode: %v", vid, ifname, reqVlanMode))
 		}
 	} else {
 		vlanMode, err := this.transConfigLookupTbl.getVlanModeEthIntf(ifname)
@@ -534,15 +534,15 @@ func (this *ConfigMngrT) validateSetNativeVlanEthIntfChange(changeItem *DiffChan
 		}
 
 		if vlanMode != oc.OpenconfigVlan_VlanModeType_TRUNK {
-			return fmt.Errorf("Set native VLAN %d for Ethernet interface %s is disallowed if VLAN interface mode is not trunk. Current mode: %v", vid, ifname, vlanMode)
+			return newValidationErr(ValidationReasonUnsupportedC, fmt.Errorf("Set native VLAN %d for Ethernet interface %s is disallowed if VLAN interface mode is not trunk. Current mode: %v", vid, ifname, vlanMode))
 		}
 	}
 
 	log.Infof("Requested set native VLAN %d for Ethernet interface %s", vid, ifname)
 	setNativeVlanEthIntfCmd := cmd.NewSetNativeVlanEthIntfCmdT(changeItem.Change, this.ethSwitchMgmtClient)
 	if err := this.transConfigLookupTbl.checkDependenciesForSetNativeVlanForEthIntf(ifname, vid); err != nil {
-		return fmt.Errorf("Cannot %q because there are dependencies from interface %s:\n%s",
-			setNativeVlanEthIntfCmd.GetName(), ifname, err)
+		return newValidationErr(ValidationReasonDependencyC, fmt.Errorf("Cannot %q because there are dependencies from interface %s:\n%s",
+			setNativeVlanEthIntfCmd.GetName(), ifname, err))
 	}
 
 	if this.transHasBeenStarted {
@@ -573,7 +573,7 @@ func (this *ConfigMngrT) validateSetNativeVlanEthIntfChange(changeItem *DiffChan
 func (this *ConfigMngrT) validateDeleteNativeVlanEthIntfChange(changeItem *DiffChangeMgmtT, changelog *DiffChangelogMgmtT) error {
 	ifname := changeItem.Change.Path[cmd.VlanEthIfnamePathItemIdxC]
 	if !this.isEthIntfAvailable(ifname) {
-		return fmt.Errorf("Ethernet interface %s is not available", ifname)
+		return newValidationErr(ValidationReasonUnavailableC, fmt.Errorf("Ethernet interface %s is not available", ifname))
 	}
 
 	vid16, err := utils.ConvertGoInterfaceIntoUint16(changeItem.Change.From)
@@ -584,7 +584,7 @@ func (this *ConfigMngrT) validateDeleteNativeVlanEthIntfChange(changeItem *DiffC
 	vid := lib.VidT(vid16)
 	vlanMode, err := this.transConfigLookupTbl.getVlanModeEthIntf(ifname)
 	if vlanMode != oc.OpenconfigVlan_VlanModeType_TRUNK {
-		return fmt.Errorf("Deletion of native VLAN %d from Ethernet interface %s is disallowed if VLAN interface mode is not trunk. Current mode: %v", vid, ifname, vlanMode)
+		return newValidationErr(ValidationReasonUnsupportedC, fmt.Errorf("Deletion of native VLAN %d from Ethernet interface %s is disallowed if VLAN interface mode is not trunk. Current mode: %v", vid, ifname, vlanMode))
 	}
 
 	var newChange diff.Change
@@ -603,8 +603,8 @@ func (this *ConfigMngrT) validateDeleteNativeVlanEthIntfChange(changeItem *DiffC
 	log.Infof("Requested delete native VLAN %d from Ethernet interface %s", vid, ifname)
 	deleteNativeVlanEthIntfCmd := cmd.NewDeleteNativeVlanEthIntfCmdT(changeItem.Change, this.ethSwitchMgmtClient)
 	if err := this.transConfigLookupTbl.checkDependenciesForDeleteNativeVlanFromEthIntf(ifname, vid); err != nil {
-		return fmt.Errorf("Cannot %q because there are dependencies from interface %s:\n%s",
-			deleteNativeVlanEthIntfCmd.GetName(), ifname, err)
+		return newValidationErr(ValidationReasonDependencyC, fmt.Errorf("Cannot %q because there are dependencies from interface %s:\n%s",
+			deleteNativeVlanEthIntfCmd.GetName(), ifname, err))
 	}
 
 	if this.transHasBeenStarted {
@@ -642,7 +642,7 @@ func (this *ConfigMngrT) validateDeleteNativeVlanEthIntfChange(changeItem *DiffC
 func (this *ConfigMngrT) validateSetTrunkVlanEthIntfChange(changeItem *DiffChangeMgmtT, changelog *DiffChangelogMgmtT) error {
 	ifname := changeItem.Change.Path[cmd.VlanEthIfnamePathItemIdxC]
 	if !this.isEthIntfAvailable(ifname) {
-		return fmt.Errorf("Ethernet interface %s is not available", ifname)
+		return newValidationErr(ValidationReasonUnavailableC, fmt.Errorf("Ethernet interface %s is not available", ifname))
 	}
 
 	vid16, err := utils.ConvertGoInterfaceIntoUint16(changeItem.Change.To)
@@ -655,7 +655,7 @@ func (this *ConfigMngrT) validateSetTrunkVlanEthIntfChange(changeItem *DiffChang
 	if exists {
 		reqVlanMode := oc.E_OpenconfigVlan_VlanModeType(*vlanModeChange.Change.To.(*uint8))
 		if reqVlanMode != oc.OpenconfigVlan_VlanModeType_TRUNK {
-			return fmt.Errorf("Set trunk VLAN %d for Ethernet interface %s is disallowed if VLAN interface mode is not going to be trunk.\nRequested mode: %v", vid, ifname, reqVlanMode)
+			return newValidationErr(ValidationReasonUnsupportedC, fmt.Errorf("Set trunk VLAN %d for Ethernet interface %s is disallowed if VLAN interface mode is not going to be trunk.\nRequested mode: %v", vid, ifname, reqVlanMode))
 		}
 	} else {
 		vlanMode, err := this.transConfigLookupTbl.getVlanModeEthIntf(ifname)
@@ -664,15 +664,15 @@ func (this *ConfigMngrT) validateSetTrunkVlanEthIntfChange(changeItem *DiffChang
 		}
 
 		if vlanMode != oc.OpenconfigVlan_VlanModeType_TRUNK {
-			return fmt.Errorf("Set trunk VLAN %d for Ethernet interface %s is disallowed if VLAN interface mode is not trunk. Current mode: %v", vid, ifname, vlanMode)
+			return newValidationErr(ValidationReasonUnsupportedC, fmt.Errorf("Set trunk VLAN %d for Ethernet interface %s is disallowed if VLAN interface mode is not trunk. Current mode: %v", vid, ifname, vlanMode))
 		}
 	}
 
 	log.Infof("Requested set trunk VLAN %d from Ethernet interface %s", vid, ifname)
 	setTrunkVlanEthIntfCmd := cmd.NewSetTrunkVlanEthIntfCmdT(changeItem.Change, this.ethSwitchMgmtClient)
 	if err := this.transConfigLookupTbl.checkDependenciesForSetTrunkVlanForEthIntf(ifname, vid); err != nil {
-		return fmt.Errorf("Cannot %q because there are dependencies from interface %s:\n%s",
-			setTrunkVlanEthIntfCmd.GetName(), ifname, err)
+		return newValidationErr(ValidationReasonDependencyC, fmt.Errorf("Cannot %q because there are dependencies from interface %s:\n%s",
+			setTrunkVlanEthIntfCmd.GetName(), ifname, err))
 	}
 
 	if this.transHasBeenStarted {
@@ -706,7 +706,7 @@ func (this *ConfigMngrT) validateSetTrunkVlanEthIntfChange(changeItem *DiffChang
 func (this *ConfigMngrT) validateDeleteTrunkVlanEthIntfChange(changeItem *DiffChangeMgmtT, changelog *DiffChangelogMgmtT) error {
 	ifname := changeItem.Change.Path[cmd.VlanEthIfnamePathItemIdxC]
 	if !this.isEthIntfAvailable(ifname) {
-		return fmt.Errorf("Ethernet interface %s is not available", ifname)
+		return newValidationErr(ValidationReasonUnavailableC, fmt.Errorf("Ethernet interface %s is not available", ifname))
 	}
 
 	vid16, err := utils.ConvertGoInterfaceIntoUint16(changeItem.Change.From)
@@ -717,7 +717,7 @@ func (this *ConfigMngrT) validateDeleteTrunkVlanEthIntfChange(changeItem *DiffCh
 	vid := lib.VidT(vid16)
 	vlanMode, err := this.transConfigLookupTbl.getVlanModeEthIntf(ifname)
 	if vlanMode != oc.OpenconfigVlan_VlanModeType_TRUNK {
-		return fmt.Errorf("Deletion of trunk VLAN %d from Ethernet interface %s is disallowed if VLAN interface mode is not trunk. Current mode: %v", vid, ifname, vlanMode)
+		return newValidationErr(ValidationReasonUnsupportedC, fmt.Errorf("Deletion of trunk VLAN %d from Ethernet interface %s is disallowed if VLAN interface mode is not trunk. Current mode: %v", vid, ifname, vlanMode))
 	}
 
 	var newChange diff.Change
@@ -738,8 +738,8 @@ func (this *ConfigMngrT) validateDeleteTrunkVlanEthIntfChange(changeItem *DiffCh
 	log.Infof("Requested delete trunk VLAN %d from Ethernet interface %s", vid, ifname)
 	deleteTrunkVlanEthIntfCmd := cmd.NewDeleteTrunkVlanEthIntfCmdT(changeItem.Change, this.ethSwitchMgmtClient)
 	if err := this.transConfigLookupTbl.checkDependenciesForDeleteTrunkVlanFromEthIntf(ifname, vid); err != nil {
-		return fmt.Errorf("Cannot %q because there are dependencies from interface %s:\n%s",
-			deleteTrunkVlanEthIntfCmd.GetName(), ifname, err)
+		return newValidationErr(ValidationReasonDependencyC, fmt.Errorf("Cannot %q because there are dependencies from interface %s:\n%s",
+			deleteTrunkVlanEthIntfCmd.GetName(), ifname, err))
 	}
 
 	if this.transHasBeenStarted {
@@ -783,7 +783,7 @@ func (this *ConfigMngrT) processSetVlanModeEthIntfFromChangelog(changelog *DiffC
 		// Repeat till there is not any change related to set native VLAN for Ethernet interface
 		if change, exists := findSetVlanModeEthIntfChange(changelog); exists {
 			if err := this.validateSetVlanModeEthIntfChange(change, changelog); err != nil {
-				return err
+				return newChangeValidationErr(change, err)
 			}
 		} else {
 			break
@@ -802,7 +802,7 @@ func (this *ConfigMngrT) processSetAccessVlanEthIntfFromChangelog(changelog *Dif
 		// Repeat till there is not any change related to set native VLAN for Ethernet interface
 		if change, exists := findSetAccessVlanEthIntfChange(changelog); exists {
 			if err := this.validateSetAccessVlanEthIntfChange(change, changelog); err != nil {
-				return err
+				return newChangeValidationErr(change, err)
 			}
 		} else {
 			break
@@ -821,7 +821,7 @@ func (this *ConfigMngrT) processDeleteAccessVlanEthIntfFromChangelog(changelog *
 		// Repeat till there is not any change related to delete native VLAN from Ethernet interface
 		if change, exists := findDeleteAccessVlanEthIntfChange(changelog); exists {
 			if err := this.validateDeleteAccessVlanEthIntfChange(change, changelog); err != nil {
-				return err
+				return newChangeValidationErr(change, err)
 			}
 		} else {
 			break
@@ -840,7 +840,7 @@ func (this *ConfigMngrT) processSetNativeVlanEthIntfFromChangelog(changelog *Dif
 		// Repeat till there is not any change related to set native VLAN for Ethernet interface
 		if change, exists := findSetNativeVlanEthIntfChange(changelog); exists {
 			if err := this.validateSetNativeVlanEthIntfChange(change, changelog); err != nil {
-				return err
+				return newChangeValidationErr(change, err)
 			}
 		} else {
 			break
@@ -859,7 +859,7 @@ func (this *ConfigMngrT) processDeleteNativeVlanEthIntfFromChangelog(changelog *
 		// Repeat till there is not any change related to delete native VLAN from Ethernet interface
 		if change, exists := findDeleteNativeVlanEthIntfChange(changelog); exists {
 			if err := this.validateDeleteNativeVlanEthIntfChange(change, changelog); err != nil {
-				return err
+				return newChangeValidationErr(change, err)
 			}
 		} else {
 			break
@@ -878,7 +878,7 @@ func (this *ConfigMngrT) processSetTrunkVlanEthIntfFromChangelog(changelog *Diff
 		// Repeat till there is not any change related to set trunk VLAN for Ethernet interface
 		if change, exists := findSetTrunkVlanEthIntfChange(changelog); exists {
 			if err := this.validateSetTrunkVlanEthIntfChange(change, changelog); err != nil {
-				return err
+				return newChangeValidationErr(change, err)
 			}
 		} else {
 			break
@@ -897,7 +897,7 @@ func (this *ConfigMngrT) processDeleteTrunkVlanEthIntfFromChangelog(changelog *D
 		// Repeat till there is not any change related to delete trunk VLANs from Ethernet interface
 		if change, exists := findDeleteTrunkVlanEthIntfChange(changelog); exists {
 			if err := this.validateDeleteTrunkVlanEthIntfChange(change, changelog); err != nil {
-				return err
+				return newChangeValidationErr(change, err)
 			}
 		} else {
 			break
@@ -959,7 +959,7 @@ func (this *ConfigMngrT) setVlanEthIntf(device *oc.Device) error {
 
 				if !createdVlans.Has(accessVlan) {
 					fmt.Printf("Access VLAN %d does not exist. Creating...", accessVlan)
-					if err := this.CreateVlanCmd(accessVlan); err != nil {
+					if err := this.createVlanCmd(accessVlan); err != nil {
 						return err
 					}
 
@@ -988,7 +988,7 @@ func (this *ConfigMngrT) setVlanEthIntf(device *oc.Device) error {
 
 				if !createdVlans.Has(nativeVlan) {
 					fmt.Printf("Native VLAN %d does not exist. Creating...", nativeVlan)
-					if err := this.CreateVlanCmd(nativeVlan); err != nil {
+					if err := this.createVlanCmd(nativeVlan); err != nil {
 						return err
 					}
 
@@ -1017,7 +1017,7 @@ func (this *ConfigMngrT) setVlanEthIntf(device *oc.Device) error {
 
 				if !createdVlans.Has(trunkVlan) {
 					fmt.Printf("Trunk VLAN %d does not exist. Creating...", trunkVlan)
-					if err := this.CreateVlanCmd(trunkVlan); err != nil {
+					if err := this.createVlanCmd(trunkVlan); err != nil {
 						return err
 					}
 
@@ -1030,7 +1030,7 @@ func (this *ConfigMngrT) setVlanEthIntf(device *oc.Device) error {
 	return nil
 }
 
-func (this *ConfigMngrT) CreateVlanCmd(vid lib.VidT) error {
+func (this *ConfigMngrT) createVlanCmd(vid lib.VidT) error {
 	var newChange diff.Change
 	newChange.Type = diff.CREATE
 	newChange.From = nil
@@ -0,0 +1,60 @@
+package gnmi
+
+import (
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+	ext "github.com/openconfig/gnmi/proto/gnmi_ext"
+)
+
+// BestEffortResult is returned by ConfigCallback in ApplyResult, when changes
+// have been committed in best-effort mode and some of them have been applied.
+// Callback withdraws changes of failed groups from the candidate config, so the
+// candidate becomes the config of the server. Set succeeds and returns the
+// report of groups in experimental extension of response of type
+// ExperimentalExtensionBestEffortReport.
+type BestEffortResult struct {
+	Groups []CommitGroupResult `json:"groups"`
+}
+
+// CommitGroupResult is the result of commit of changes of independent
+// interfaces.
+type CommitGroupResult struct {
+	Interfaces []string `json:"interfaces"`
+	Commands   []string `json:"commands"`        // Commands in order they have been executed
+	Error      string   `json:"error,omitempty"` // Empty if changes of group have been applied
+}
+
+// FailedGroups returns the number of groups, which changes have been
+// withdrawn.
+func (r *BestEffortResult) FailedGroups() int {
+	failed := 0
+	for _, group := range r.Groups {
+		if group.Error != "" {
+			failed++
+		}
+	}
+	return failed
+}
+
+func (r *BestEffortResult) String() string {
+	return fmt.Sprintf("best-effort commit of %d groups, %d failed", len(r.Groups), r.FailedGroups())
+}
+
+// bestEffortResponse returns response of Set, which changes have been applied
+// by best-effort commit.
+func bestEffortResponse(req *pb.SetRequest, results []*pb.UpdateResult, result *BestEffortResult) (*pb.SetResponse, error) {
+	reportExt, err := newExperimentalExtension(ExperimentalExtensionBestEffortReport, result)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error in marshaling report of best-effort commit: %v", err)
+	}
+
+	return &pb.SetResponse{
+		Prefix:    req.GetPrefix(),
+		Response:  results,
+		Extension: []*ext.Extension{reportExt},
+	}, nil
+}
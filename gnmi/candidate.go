@@ -0,0 +1,394 @@
+package gnmi
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	log "github.com/golang/glog"
+	"github.com/golang/protobuf/proto"
+	"github.com/openconfig/ygot/ygot"
+	"github.com/r3labs/diff"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+	ext "github.com/openconfig/gnmi/proto/gnmi_ext"
+)
+
+// CandidateTargetPrefix is the prefix of target of Get and Set requests, which
+// selects private named candidate config of the requesting user, e.g.
+// "candidate:vlans". Candidates are kept in memory only.
+const CandidateTargetPrefix = "candidate:"
+
+const (
+	// maxCandidatesPerUser is the number of candidates, which can be kept by
+	// single user at once.
+	maxCandidatesPerUser = 8
+	// maxCandidates is the number of candidates, which can be kept by all
+	// users at once.
+	maxCandidates = 64
+)
+
+// CandidateAction is the action requested on named candidate.
+type CandidateAction string
+
+const (
+	// CandidateActionCommit applies candidate to the device and drops it.
+	CandidateActionCommit CandidateAction = "commit"
+	// CandidateActionDiscard drops candidate.
+	CandidateActionDiscard CandidateAction = "discard"
+	// CandidateActionDiff returns changes of candidate against the config of
+	// the device.
+	CandidateActionDiff CandidateAction = "diff"
+)
+
+// CandidateRequest is the action on named candidate requested by Set in
+// experimental extension of type ExperimentalExtensionCandidateRequest.
+type CandidateRequest struct {
+	Action CandidateAction `json:"action"`
+	// Merge requests to apply changes of candidate on top of the config of
+	// the device, which has changed since candidate has been created.
+	Merge bool `json:"merge"`
+}
+
+// CandidateDiff is the diff of candidate with the config of the device
+// returned in experimental extension of type ExperimentalExtensionCandidateDiff.
+type CandidateDiff struct {
+	Changes []CandidateChange `json:"changes"`
+	// Stale means that the config of the device has changed since candidate
+	// has been created, so it can be committed with merge only.
+	Stale bool `json:"stale"`
+}
+
+// CandidateChange is the change of single node in candidate.
+type CandidateChange struct {
+	Type string      `json:"type"`
+	Path string      `json:"path"` // gNMI path of changed node
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// RequestUserFunc returns user, who has sent the request.
+type RequestUserFunc func(ctx context.Context) string
+
+// CandidateDiffCallback returns changes of config to against config from. It
+// leaves out nodes maintained by the device itself, e.g. history of commits,
+// which do not make candidate stale.
+type CandidateDiffCallback func(from, to ygot.ValidatedGoStruct) (diff.Changelog, error)
+
+// candidateKey identifies candidate, which is private for its owner.
+type candidateKey struct {
+	user string
+	name string
+}
+
+// namedCandidate is config edited over several Sets before it is committed.
+type namedCandidate struct {
+	config   ygot.ValidatedGoStruct
+	base     ygot.ValidatedGoStruct // config of the server, when candidate has been created
+	requests []*pb.SetRequest       // requests changing candidate, which are replayed by merge
+}
+
+// SetRequestUserFunc sets function identifying owner of named candidates.
+// Without it all requests share candidates.
+func (s *Server) SetRequestUserFunc(requestUser RequestUserFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requestUser = requestUser
+}
+
+// SetCandidateDiffCallback sets callback comparing candidates with the config
+// of the server. Without it every change of config is taken into account.
+func (s *Server) SetCandidateDiffCallback(callback CandidateDiffCallback) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.candidateDiff = callback
+}
+
+// candidateName returns name of candidate selected by target of prefix, or
+// empty string if request targets the config of the device.
+func candidateName(prefix *pb.Path) (string, error) {
+	if !strings.HasPrefix(prefix.GetTarget(), CandidateTargetPrefix) {
+		return "", nil
+	}
+	name := strings.TrimPrefix(prefix.GetTarget(), CandidateTargetPrefix)
+	if name == "" {
+		return "", status.Errorf(codes.InvalidArgument, "missing name of candidate in target %q", prefix.GetTarget())
+	}
+	return name, nil
+}
+
+// candidateRequest returns the action on candidate requested by extensions of
+// Set, or nil if there is no such one.
+func candidateRequest(extensions []*ext.Extension) (*CandidateRequest, error) {
+	var candidateReq *CandidateRequest
+	for _, e := range extensions {
+		msg, err := experimentalExtensionMsg(e, ExperimentalExtensionCandidateRequest)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		if msg == nil {
+			continue
+		}
+		if candidateReq != nil {
+			return nil, status.Error(codes.InvalidArgument, "more than one candidate extension in request")
+		}
+		candidateReq = &CandidateRequest{}
+		if err := json.Unmarshal(msg, candidateReq); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "error in parsing candidate extension: %v", err)
+		}
+	}
+	return candidateReq, nil
+}
+
+func (s *Server) candidateKey(ctx context.Context, name string) candidateKey {
+	key := candidateKey{name: name}
+	if s.requestUser != nil {
+		key.user = s.requestUser(ctx)
+	}
+	return key
+}
+
+// targetConfig returns config selected by target of prefix. Caller must hold
+// s.mu.
+func (s *Server) targetConfig(ctx context.Context, prefix *pb.Path) (ygot.GoStruct, error) {
+	name, grpcStatusError := candidateName(prefix)
+	if grpcStatusError != nil {
+		return nil, grpcStatusError
+	}
+	if name == "" {
+		return s.config, nil
+	}
+	candidate, ok := s.candidates[s.candidateKey(ctx, name)]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "candidate %q not found", name)
+	}
+	return candidate.config, nil
+}
+
+// setCandidate applies operations of the request to named candidate, which is
+// created from the config of the server if it does not exist yet, or carries
+// out the action on candidate requested by extension. Caller must hold s.mu.
+func (s *Server) setCandidate(ctx context.Context, name string, req *pb.SetRequest) (*pb.SetResponse, error) {
+	commitReq, grpcStatusError := commitRequest(req.GetExtension())
+	if grpcStatusError != nil {
+		return nil, grpcStatusError
+	}
+	if commitReq != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "commit extension is not supported for candidate %q", name)
+	}
+	candidateReq, grpcStatusError := candidateRequest(req.GetExtension())
+	if grpcStatusError != nil {
+		return nil, grpcStatusError
+	}
+
+	key := s.candidateKey(ctx, name)
+	candidate, ok := s.candidates[key]
+	if candidateReq != nil {
+		if len(req.GetDelete()) > 0 || len(req.GetReplace()) > 0 || len(req.GetUpdate()) > 0 || len(req.GetUnionReplace()) > 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "action %q on candidate %q cannot carry operations", candidateReq.Action, name)
+		}
+		if !ok {
+			return nil, status.Errorf(codes.NotFound, "candidate %q not found", name)
+		}
+		switch candidateReq.Action {
+		case CandidateActionCommit:
+			return s.commitCandidate(req, key, candidate, candidateReq.Merge)
+		case CandidateActionDiscard:
+			delete(s.candidates, key)
+			log.Infof("candidate %q of user %q has been discarded", name, key.user)
+			return &pb.SetResponse{Prefix: req.GetPrefix()}, nil
+		case CandidateActionDiff:
+			return s.candidateDiffResponse(req, candidate)
+		default:
+			return nil, status.Errorf(codes.InvalidArgument, "unsupported action %q on candidate %q", candidateReq.Action, name)
+		}
+	}
+
+	if !ok {
+		if grpcStatusError := s.checkCandidateLimits(key.user); grpcStatusError != nil {
+			return nil, grpcStatusError
+		}
+		base, err := ygot.DeepCopy(s.config)
+		if err != nil {
+			msg := fmt.Sprintf("error in copying config struct: %v", err)
+			log.Error(msg)
+			return nil, status.Error(codes.Internal, msg)
+		}
+		// Neither of them is modified in place, so they can share the copy
+		candidate = &namedCandidate{
+			config: base.(ygot.ValidatedGoStruct),
+			base:   base.(ygot.ValidatedGoStruct),
+		}
+	}
+
+	// The tree is built from scratch, so changes do not affect candidate
+	jsonTree, err := ygot.ConstructIETFJSON(candidate.config, &ygot.RFC7951JSONConfig{})
+	if err != nil {
+		msg := fmt.Sprintf("error in constructing IETF JSON tree from config struct: %v", err)
+		log.Error(msg)
+		return nil, status.Error(codes.Internal, msg)
+	}
+	results, changed, grpcStatusError := s.applySetOperations(jsonTree, req)
+	if grpcStatusError != nil {
+		return nil, grpcStatusError
+	}
+	if changed {
+		config, grpcStatusError := s.newConfigStruct(jsonTree)
+		if grpcStatusError != nil {
+			return nil, grpcStatusError
+		}
+		candidate.config = config
+		candidate.requests = append(candidate.requests, proto.Clone(req).(*pb.SetRequest))
+	}
+	if !ok {
+		log.Infof("candidate %q of user %q has been created", name, key.user)
+	}
+	s.candidates[key] = candidate
+
+	return &pb.SetResponse{
+		Prefix:   req.GetPrefix(),
+		Response: results,
+	}, nil
+}
+
+// checkCandidateLimits checks whether user is allowed to create one more
+// candidate. Caller must hold s.mu.
+func (s *Server) checkCandidateLimits(user string) error {
+	if len(s.candidates) >= maxCandidates {
+		return status.Errorf(codes.ResourceExhausted, "limit of %d candidates has been reached", maxCandidates)
+	}
+	userCandidates := 0
+	for key := range s.candidates {
+		if key.user == user {
+			userCandidates++
+		}
+	}
+	if userCandidates >= maxCandidatesPerUser {
+		return status.Errorf(codes.ResourceExhausted, "limit of %d candidates of user %q has been reached", maxCandidatesPerUser, user)
+	}
+	return nil
+}
+
+// commitCandidate applies candidate to the device. Candidate is rejected if
+// the config of the server has changed since candidate has been created,
+// unless merge is requested. Caller must hold s.mu.
+func (s *Server) commitCandidate(req *pb.SetRequest, key candidateKey, candidate *namedCandidate, merge bool) (*pb.SetResponse, error) {
+	stale, grpcStatusError := s.isCandidateStale(candidate)
+	if grpcStatusError != nil {
+		return nil, grpcStatusError
+	}
+	config := candidate.config
+	if stale {
+		if !merge {
+			return nil, status.Errorf(codes.FailedPrecondition, "config has changed since candidate %q has been created, commit it with merge", key.name)
+		}
+		if config, grpcStatusError = s.mergeCandidate(candidate); grpcStatusError != nil {
+			return nil, status.Errorf(codes.Aborted, "error in merging candidate %q: %v", key.name, status.Convert(grpcStatusError).Message())
+		}
+	}
+
+	resp, applied, grpcStatusError := s.applyConfig(req, nil, config, nil)
+	if grpcStatusError != nil {
+		return nil, grpcStatusError
+	}
+	// Dry run keeps candidate for further changes
+	if applied {
+		delete(s.candidates, key)
+		log.Infof("candidate %q of user %q has been committed", key.name, key.user)
+	}
+	return resp, nil
+}
+
+// isCandidateStale checks whether the config of the server has changed since
+// candidate has been created.
+func (s *Server) isCandidateStale(candidate *namedCandidate) (bool, error) {
+	changelog, grpcStatusError := s.diffCandidateConfig(candidate.base, s.config)
+	if grpcStatusError != nil {
+		return false, grpcStatusError
+	}
+	return len(changelog) > 0, nil
+}
+
+// diffCandidateConfig returns changes of config to against config from. Nodes
+// left out by the candidate diff callback are not taken into account.
+func (s *Server) diffCandidateConfig(from, to ygot.ValidatedGoStruct) (diff.Changelog, error) {
+	var changelog diff.Changelog
+	var err error
+	if s.candidateDiff != nil {
+		changelog, err = s.candidateDiff(from, to)
+	} else {
+		changelog, err = diff.Diff(from, to)
+	}
+	if err != nil {
+		msg := fmt.Sprintf("error in getting diff of config change: %v", err)
+		log.Error(msg)
+		return nil, status.Error(codes.Internal, msg)
+	}
+	return changelog, nil
+}
+
+// mergeCandidate replays requests changing candidate on the current config of
+// the server.
+func (s *Server) mergeCandidate(candidate *namedCandidate) (ygot.ValidatedGoStruct, error) {
+	jsonTree, err := ygot.ConstructIETFJSON(s.config, &ygot.RFC7951JSONConfig{})
+	if err != nil {
+		msg := fmt.Sprintf("error in constructing IETF JSON tree from config struct: %v", err)
+		log.Error(msg)
+		return nil, status.Error(codes.Internal, msg)
+	}
+	for _, req := range candidate.requests {
+		if _, _, grpcStatusError := s.applySetOperations(jsonTree, req); grpcStatusError != nil {
+			return nil, grpcStatusError
+		}
+	}
+	return s.newConfigStruct(jsonTree)
+}
+
+// candidateDiffResponse returns response of Set carrying the diff of
+// candidate in extension.
+func (s *Server) candidateDiffResponse(req *pb.SetRequest, candidate *namedCandidate) (*pb.SetResponse, error) {
+	changelog, grpcStatusError := s.diffCandidateConfig(s.config, candidate.config)
+	if grpcStatusError != nil {
+		return nil, grpcStatusError
+	}
+	stale, grpcStatusError := s.isCandidateStale(candidate)
+	if grpcStatusError != nil {
+		return nil, grpcStatusError
+	}
+
+	candidateDiff := &CandidateDiff{
+		Changes: make([]CandidateChange, 0, len(changelog)),
+		Stale:   stale,
+	}
+	for _, change := range changelog {
+		path := strings.Join(change.Path, "/")
+		config := candidate.config
+		if change.Type == diff.DELETE {
+			config = s.config
+		}
+		if elems, _, _, err := s.model.resolveChangePath(config, change.Path); err == nil {
+			if p, err := ygot.PathToString(&pb.Path{Elem: elems}); err == nil {
+				path = p
+			}
+		}
+		candidateDiff.Changes = append(candidateDiff.Changes, CandidateChange{
+			Type: change.Type,
+			Path: path,
+			From: change.From,
+			To:   change.To,
+		})
+	}
+
+	diffExt, err := newExperimentalExtension(ExperimentalExtensionCandidateDiff, candidateDiff)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error in marshaling diff of candidate: %v", err)
+	}
+
+	return &pb.SetResponse{
+		Prefix:    req.GetPrefix(),
+		Extension: []*ext.Extension{diffExt},
+	}, nil
+}
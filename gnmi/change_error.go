@@ -0,0 +1,50 @@
+package gnmi
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/openconfig/ygot/ygot"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// ChangeError is returned by ConfigCallback to reject a particular change of
+// config. Set reports it to the client as a field violation of the path of
+// the change.
+type ChangeError struct {
+	Path   []string // Path of change in changelog, i.e. GoStruct field names and keys of lists
+	Reason string   // Reason code of rejecting change
+	Err    error    // Err describes the problem
+}
+
+func (e *ChangeError) Error() string {
+	return fmt.Sprintf("change of %s rejected (%s): %v", strings.Join(e.Path, "/"), e.Reason, e.Err)
+}
+
+// changeErrorStatus converts error of change into grpc status error carrying
+// BadRequest details with violation of path of change in newConfig.
+func (m *Model) changeErrorStatus(changeErr *ChangeError, newConfig ygot.GoStruct) error {
+	field := strings.Join(changeErr.Path, "/")
+	if elems, _, _, err := m.resolveChangePath(newConfig, changeErr.Path); err == nil {
+		if path, err := ygot.PathToString(&pb.Path{Elem: elems}); err == nil {
+			field = path
+		}
+	}
+
+	st := status.New(codes.InvalidArgument, fmt.Sprintf("error in validating change of %s: %v", field, changeErr.Err))
+	detailed, err := st.WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{{
+			Field:       field,
+			Description: fmt.Sprintf("%s: %v", changeErr.Reason, changeErr.Err),
+		}},
+	})
+	if err != nil {
+		return st.Err()
+	}
+
+	return detailed.Err()
+}
@@ -0,0 +1,272 @@
+package gnmi
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/openconfig/goyang/pkg/yang"
+	"github.com/openconfig/ygot/ygot"
+	"github.com/r3labs/diff"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// changelogNotification converts changelog of config struct into a single
+// notification. Every changed node, which still exists in newConfig, is
+// reported by updates of all its leaves. Every node, which does not exist
+// anymore, is reported as deleted.
+func (m *Model) changelogNotification(changelog diff.Changelog, newConfig ygot.GoStruct) (*pb.Notification, error) {
+	n := &pb.Notification{Timestamp: time.Now().UnixNano()}
+	updated := make(map[string]bool)
+	deleted := make(map[string]bool)
+	for _, change := range changelog {
+		elems, schema, node, err := m.resolveChangePath(newConfig, change.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve path %v of change: %v", change.Path, err)
+		}
+
+		updates, err := m.appendLeafUpdates(nil, elems, schema, node)
+		if err != nil {
+			return nil, err
+		}
+		if len(updates) == 0 {
+			path := &pb.Path{Elem: elems}
+			key := proto.CompactTextString(path)
+			if !deleted[key] {
+				deleted[key] = true
+				n.Delete = append(n.Delete, path)
+			}
+			continue
+		}
+
+		for _, update := range updates {
+			key := proto.CompactTextString(update.GetPath())
+			if !updated[key] {
+				updated[key] = true
+				n.Update = append(n.Update, update)
+			}
+		}
+	}
+
+	return n, nil
+}
+
+// resolveChangePath translates path of change, which is made of GoStruct field
+// names and keys of lists, into gNMI path elements of schema. It returns also
+// schema entry and value of node pointed by path in config. The value is not
+// valid if node does not exist. Change of single leaf-list element is resolved
+// into the whole leaf-list.
+func (m *Model) resolveChangePath(config ygot.GoStruct, path []string) ([]*pb.PathElem, *yang.Entry, reflect.Value, error) {
+	schema := m.schemaTreeRoot
+	node := reflect.ValueOf(config)
+	nodeType := node.Type()
+	var elems []*pb.PathElem
+	for i := 0; i < len(path); i++ {
+		if nodeType.Kind() != reflect.Ptr || nodeType.Elem().Kind() != reflect.Struct {
+			return nil, nil, reflect.Value{}, fmt.Errorf("unexpected element %q", path[i])
+		}
+		field, ok := nodeType.Elem().FieldByName(path[i])
+		if !ok {
+			return nil, nil, reflect.Value{}, fmt.Errorf("field %q not found in %v", path[i], nodeType.Elem().Name())
+		}
+		var err error
+		if elems, schema, err = appendSchemaPathElems(elems, schema, field); err != nil {
+			return nil, nil, reflect.Value{}, err
+		}
+
+		nodeType = field.Type
+		if node.IsValid() && !node.IsNil() {
+			node = node.Elem().FieldByIndex(field.Index)
+		} else {
+			node = reflect.Value{}
+		}
+
+		switch nodeType.Kind() {
+		case reflect.Map:
+			if i+1 == len(path) {
+				break
+			}
+			i++
+			if elems, err = setListKey(elems, schema, path[i]); err != nil {
+				return nil, nil, reflect.Value{}, err
+			}
+			node = getMapEntry(node, path[i])
+			nodeType = nodeType.Elem()
+		case reflect.Slice:
+			if nodeType.Elem().Kind() != reflect.Uint8 {
+				i = len(path)
+			}
+		}
+	}
+
+	return elems, schema, node, nil
+}
+
+// appendLeafUpdates appends updates of all leaves set in node, which is placed
+// at elems path of schema.
+func (m *Model) appendLeafUpdates(updates []*pb.Update, elems []*pb.PathElem, schema *yang.Entry, node reflect.Value) ([]*pb.Update, error) {
+	if !node.IsValid() || isZeroLeaf(node) {
+		return updates, nil
+	}
+
+	if _, ok := node.Interface().(ygot.GoStruct); ok {
+		structType := node.Elem().Type()
+		for i := 0; i < structType.NumField(); i++ {
+			field := structType.Field(i)
+			if !isSchemaField(field) {
+				continue
+			}
+			fieldElems, fieldSchema, err := appendSchemaPathElems(elems, schema, field)
+			if err != nil {
+				return nil, err
+			}
+			if updates, err = m.appendLeafUpdates(updates, fieldElems, fieldSchema, node.Elem().Field(i)); err != nil {
+				return nil, err
+			}
+		}
+		return updates, nil
+	}
+
+	if node.Kind() == reflect.Map {
+		for _, key := range node.MapKeys() {
+			entryElems, err := setListKey(elems, schema, fmt.Sprint(key.Interface()))
+			if err != nil {
+				return nil, err
+			}
+			if updates, err = m.appendLeafUpdates(updates, entryElems, schema, node.MapIndex(key)); err != nil {
+				return nil, err
+			}
+		}
+		return updates, nil
+	}
+
+	val, err := m.leafTypedValue(node.Interface())
+	if err != nil {
+		return nil, fmt.Errorf("leaf node %v: %v", &pb.Path{Elem: elems}, err)
+	}
+	return append(updates, &pb.Update{Path: &pb.Path{Elem: elems}, Val: val}), nil
+}
+
+// appendSchemaPathElems returns copy of elems extended by path elements of
+// GoStruct field, together with schema entry of the field.
+func appendSchemaPathElems(elems []*pb.PathElem, schema *yang.Entry, field reflect.StructField) ([]*pb.PathElem, *yang.Entry, error) {
+	tag, ok := field.Tag.Lookup("path")
+	if !ok {
+		return nil, nil, fmt.Errorf("field %q does not have path tag", field.Name)
+	}
+	// Compressed path may have many alternatives, the first one is the config path
+	tag = strings.Split(tag, "|")[0]
+	newElems := make([]*pb.PathElem, len(elems), len(elems)+strings.Count(tag, "/")+1)
+	copy(newElems, elems)
+	for _, name := range strings.Split(tag, "/") {
+		next, ok := schema.Dir[name]
+		if !ok {
+			return nil, nil, fmt.Errorf("schema node %q not found in %q", name, schema.Name)
+		}
+		schema = next
+		newElems = append(newElems, &pb.PathElem{Name: name})
+	}
+
+	return newElems, schema, nil
+}
+
+// setListKey returns copy of elems with key of the last element set. Only
+// lists with a single key are supported.
+func setListKey(elems []*pb.PathElem, schema *yang.Entry, key string) ([]*pb.PathElem, error) {
+	if !schema.IsList() || len(elems) == 0 {
+		return nil, fmt.Errorf("schema node %q is not a list", schema.Name)
+	}
+	if strings.Contains(strings.TrimSpace(schema.Key), " ") {
+		return nil, fmt.Errorf("list %q with many keys is unsupported", schema.Name)
+	}
+	newElems := make([]*pb.PathElem, len(elems))
+	copy(newElems, elems)
+	last := newElems[len(newElems)-1]
+	newElems[len(newElems)-1] = &pb.PathElem{
+		Name: last.GetName(),
+		Key:  map[string]string{strings.TrimSpace(schema.Key): key},
+	}
+
+	return newElems, nil
+}
+
+// getMapEntry finds entry of map by key formatted as a string.
+func getMapEntry(m reflect.Value, key string) reflect.Value {
+	if !m.IsValid() || m.IsNil() {
+		return reflect.Value{}
+	}
+	for _, k := range m.MapKeys() {
+		if fmt.Sprint(k.Interface()) == key {
+			return m.MapIndex(k)
+		}
+	}
+
+	return reflect.Value{}
+}
+
+// pathsOverlap checks if one of paths is the same as or is a prefix of the
+// other one. Missing keys and "*" match any value.
+func pathsOverlap(a, b []*pb.PathElem) bool {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	for i := 0; i < n; i++ {
+		if a[i].GetName() != b[i].GetName() && a[i].GetName() != "*" && b[i].GetName() != "*" {
+			return false
+		}
+		for k, av := range a[i].GetKey() {
+			bv, ok := b[i].GetKey()[k]
+			if ok && av != bv && av != "*" && bv != "*" {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// filterChanges returns part of notification about config changes, which
+// matches ON_CHANGE subscriptions of subscriber. Paths are made relative to
+// the prefix of subscription list. Nil is returned if nothing matches.
+func (c *subscriber) filterChanges(n *pb.Notification) *pb.Notification {
+	prefix := c.list.GetPrefix().GetElem()
+	subPaths := make([][]*pb.PathElem, len(c.onChange))
+	for i, sub := range c.onChange {
+		subPaths[i] = append(append([]*pb.PathElem{}, prefix...), sub.GetPath().GetElem()...)
+	}
+
+	cn := &pb.Notification{Timestamp: n.GetTimestamp(), Prefix: c.list.GetPrefix()}
+	for _, update := range n.GetUpdate() {
+		elems := update.GetPath().GetElem()
+		for _, subPath := range subPaths {
+			if len(elems) >= len(subPath) && pathsOverlap(elems, subPath) {
+				cn.Update = append(cn.Update, &pb.Update{Path: &pb.Path{Elem: elems[len(prefix):]}, Val: update.GetVal()})
+				break
+			}
+		}
+	}
+	for _, path := range n.GetDelete() {
+		elems := path.GetElem()
+		for i, subPath := range subPaths {
+			if !pathsOverlap(elems, subPath) {
+				continue
+			}
+			if len(elems) > len(subPath) {
+				cn.Delete = append(cn.Delete, &pb.Path{Elem: elems[len(prefix):]})
+			} else {
+				// Subscribed node has been deleted together with its ancestor
+				cn.Delete = append(cn.Delete, c.onChange[i].GetPath())
+			}
+			break
+		}
+	}
+
+	if len(cn.GetUpdate()) == 0 && len(cn.GetDelete()) == 0 {
+		return nil
+	}
+	return cn
+}
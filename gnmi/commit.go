@@ -0,0 +1,191 @@
+package gnmi
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/golang/glog"
+	"github.com/openconfig/ygot/ygot"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+	ext "github.com/openconfig/gnmi/proto/gnmi_ext"
+)
+
+// CommitAction is the action requested by the gNMI commit-confirmed extension.
+type CommitAction int
+
+const (
+	// CommitActionCommit applies the config, which is rolled back unless it
+	// is confirmed within the rollback duration.
+	CommitActionCommit CommitAction = iota
+	// CommitActionConfirm applies permanently the pending commit.
+	CommitActionConfirm
+	// CommitActionCancel rolls back the pending commit.
+	CommitActionCancel
+	// CommitActionSetRollbackDuration changes the rollback duration of the
+	// pending commit.
+	CommitActionSetRollbackDuration
+)
+
+// CommitRequest describes the commit-confirmed operation requested by Set.
+type CommitRequest struct {
+	ID               string
+	Action           CommitAction
+	RollbackDuration time.Duration // zero means the default duration of the device
+}
+
+// CommitCallback is the signature of the function to apply a commit-confirmed
+// operation to the device. newConfig is set only for CommitActionCommit.
+type CommitCallback func(req *CommitRequest, newConfig ygot.ValidatedGoStruct, cbUserData interface{}) error
+
+// pendingCommit is the commit awaiting confirmation.
+type pendingCommit struct {
+	id             string
+	rollbackConfig ygot.ValidatedGoStruct // config to be restored if commit is cancelled
+}
+
+// SetCommitCallback sets callback handling requests of the commit-confirmed
+// extension. Set rejects such requests if there is no callback.
+func (s *Server) SetCommitCallback(callback CommitCallback) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.commitCallback = callback
+}
+
+// RestoreConfig replaces config of the server after the device changed it on
+// its own, i.e. because the pending commit has not been confirmed in time,
+// rollback has been requested through management transaction or scheduled
+// commit has been applied. config is dropped if isCurrent reports that it has
+// been superseded by config applied since by Set. isCurrent is checked along
+// with replacing config, so Set cannot interleave them.
+func (s *Server) RestoreConfig(config ygot.ValidatedGoStruct, isCurrent func() bool) error {
+	configCopy, err := ygot.DeepCopy(config)
+	if err != nil {
+		return fmt.Errorf("error in copying config struct: %v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if isCurrent != nil && !isCurrent() {
+		log.Info("config to restore has been superseded by config applied since, so it is dropped")
+		return nil
+	}
+	s.config = configCopy.(ygot.ValidatedGoStruct)
+	s.pendingCommit = nil
+	return nil
+}
+
+// ResumePendingCommit restores commit awaiting confirmation, which has been
+// applied to the device before restart of the server. config is the config of
+// the device awaiting confirmation.
+func (s *Server) ResumePendingCommit(id string, config, rollbackConfig ygot.ValidatedGoStruct) error {
+	configCopy, err := ygot.DeepCopy(config)
+	if err != nil {
+		return fmt.Errorf("error in copying config struct: %v", err)
+	}
+	rollbackConfigCopy, err := ygot.DeepCopy(rollbackConfig)
+	if err != nil {
+		return fmt.Errorf("error in copying config struct: %v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.config = configCopy.(ygot.ValidatedGoStruct)
+	// Commit requested without ID cannot be confirmed by commit extension
+	if id != "" {
+		s.pendingCommit = &pendingCommit{id: id, rollbackConfig: rollbackConfigCopy.(ygot.ValidatedGoStruct)}
+	}
+	return nil
+}
+
+// commitRequest returns the commit-confirmed operation carried by extensions
+// of Set, or nil if there is no such one.
+func commitRequest(exts []*ext.Extension) (*CommitRequest, error) {
+	var req *CommitRequest
+	for _, e := range exts {
+		commit := e.GetCommit()
+		if commit == nil {
+			continue
+		}
+		if req != nil {
+			return nil, status.Error(codes.InvalidArgument, "only one commit extension is allowed")
+		}
+		if commit.GetId() == "" {
+			return nil, status.Error(codes.InvalidArgument, "commit extension requires ID")
+		}
+
+		req = &CommitRequest{ID: commit.GetId()}
+		switch action := commit.GetAction().(type) {
+		case *ext.Commit_Commit:
+			req.Action = CommitActionCommit
+			if d := action.Commit.GetRollbackDuration(); d != nil {
+				req.RollbackDuration = d.AsDuration()
+			}
+		case *ext.Commit_Confirm:
+			req.Action = CommitActionConfirm
+		case *ext.Commit_Cancel:
+			req.Action = CommitActionCancel
+		case *ext.Commit_SetRollbackDuration:
+			req.Action = CommitActionSetRollbackDuration
+			req.RollbackDuration = action.SetRollbackDuration.GetRollbackDuration().AsDuration()
+		default:
+			return nil, status.Errorf(codes.InvalidArgument, "unsupported action of commit extension: %T", action)
+		}
+		if req.RollbackDuration < 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "negative rollback duration: %v", req.RollbackDuration)
+		}
+	}
+
+	return req, nil
+}
+
+// checkCommitRequest verifies if commit-confirmed operation can be requested
+// by Set req. Caller must hold s.mu.
+func (s *Server) checkCommitRequest(commitReq *CommitRequest, req *pb.SetRequest) error {
+	if s.commitCallback == nil {
+		return status.Error(codes.Unimplemented, "commit extension is not supported by the device")
+	}
+	if commitReq.Action == CommitActionCommit {
+		if s.pendingCommit != nil {
+			return status.Errorf(codes.FailedPrecondition, "commit %q is awaiting confirmation", s.pendingCommit.id)
+		}
+		return nil
+	}
+
+	if len(req.GetDelete()) > 0 || len(req.GetReplace()) > 0 || len(req.GetUpdate()) > 0 || len(req.GetUnionReplace()) > 0 {
+		return status.Error(codes.InvalidArgument, "confirm, cancel and change of rollback duration of commit cannot carry operations")
+	}
+	if s.pendingCommit == nil {
+		return status.Errorf(codes.FailedPrecondition, "there is no commit awaiting confirmation")
+	}
+	if s.pendingCommit.id != commitReq.ID {
+		return status.Errorf(codes.FailedPrecondition, "commit %q is not awaiting confirmation, pending commit is %q", commitReq.ID, s.pendingCommit.id)
+	}
+
+	return nil
+}
+
+// doCommitAction applies confirm, cancel or change of rollback duration of the
+// pending commit. Caller must hold s.mu.
+func (s *Server) doCommitAction(commitReq *CommitRequest) error {
+	if err := s.commitCallback(commitReq, nil, s.cbUserData); err != nil {
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+		return status.Errorf(codes.Aborted, "error in applying commit action to device: %v", err)
+	}
+
+	switch commitReq.Action {
+	case CommitActionConfirm:
+		log.Infof("commit %q has been confirmed", commitReq.ID)
+		s.pendingCommit = nil
+	case CommitActionCancel:
+		log.Infof("commit %q has been cancelled", commitReq.ID)
+		s.config = s.pendingCommit.rollbackConfig
+		s.pendingCommit = nil
+	}
+
+	return nil
+}
@@ -0,0 +1,183 @@
+package gnmi
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/openconfig/goyang/pkg/yang"
+	"github.com/openconfig/ygot/ygot"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// isSchemaField checks if field of GoStruct represents a schema node. Fields
+// carrying annotations of nodes are skipped.
+func isSchemaField(field reflect.StructField) bool {
+	if _, ok := field.Tag.Lookup("ygotAnnotation"); ok {
+		return false
+	}
+	_, ok := field.Tag.Lookup("path")
+	return ok
+}
+
+// schemaForPath finds schema entry of node pointed by path. Keys of path
+// elements are ignored. Nil is returned if there is no such schema node.
+func schemaForPath(root *yang.Entry, path *pb.Path) *yang.Entry {
+	schema := root
+	for _, elem := range path.GetElem() {
+		next, ok := schema.Dir[elem.GetName()]
+		if !ok {
+			return nil
+		}
+		schema = next
+	}
+
+	return schema
+}
+
+// matchesDataType checks if leaf of schema belongs to data type requested by Get.
+func matchesDataType(schema *yang.Entry, dataType pb.GetRequest_DataType) bool {
+	switch dataType {
+	case pb.GetRequest_CONFIG:
+		return !schema.ReadOnly()
+	case pb.GetRequest_STATE:
+		return schema.ReadOnly()
+	case pb.GetRequest_OPERATIONAL:
+		return schema.ReadOnly() && !hasConfigCounterpart(schema)
+	default:
+		return true
+	}
+}
+
+// hasConfigCounterpart checks if the state leaf reflects the value of config
+// leaf with the same name, i.e. .../state/mtu of .../config/mtu.
+func hasConfigCounterpart(schema *yang.Entry) bool {
+	state := schema.Parent
+	if state == nil || state.Name != "state" || state.Parent == nil {
+		return false
+	}
+	config, ok := state.Parent.Dir["config"]
+	if !ok {
+		return false
+	}
+	_, ok = config.Dir[schema.Name]
+	return ok
+}
+
+// filterDataType returns copy of node with only those leaves set, which belong
+// to data type requested by Get. Keys of list entries are preserved as long as
+// the entry has any other leaf left.
+func filterDataType(node ygot.GoStruct, schema *yang.Entry, dataType pb.GetRequest_DataType) (ygot.GoStruct, error) {
+	if dataType == pb.GetRequest_ALL {
+		return node, nil
+	}
+	schema, _, err := findStructSchema(schema, nil, node)
+	if err != nil {
+		return nil, err
+	}
+	nodeCopy, err := ygot.DeepCopy(node)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy node: %v", err)
+	}
+	if _, err := pruneDataType(reflect.ValueOf(nodeCopy), schema, dataType); err != nil {
+		return nil, err
+	}
+
+	return nodeCopy, nil
+}
+
+// pruneDataType clears leaves of GoStruct pointed by node, which do not belong
+// to data type. It returns true if there is no leaf left.
+func pruneDataType(node reflect.Value, schema *yang.Entry, dataType pb.GetRequest_DataType) (bool, error) {
+	keys := make(map[string]bool)
+	if schema.IsList() {
+		for _, key := range strings.Fields(schema.Key) {
+			keys[key] = true
+		}
+	}
+
+	empty := true
+	structValue := node.Elem()
+	for i := 0; i < structValue.NumField(); i++ {
+		field := structValue.Type().Field(i)
+		if !isSchemaField(field) {
+			continue
+		}
+		fieldValue := structValue.Field(i)
+		if isZeroLeaf(fieldValue) {
+			continue
+		}
+		_, fieldSchema, err := appendSchemaPathElems(nil, schema, field)
+		if err != nil {
+			return false, err
+		}
+
+		if _, ok := fieldValue.Interface().(ygot.GoStruct); ok {
+			childEmpty, err := pruneDataType(fieldValue, fieldSchema, dataType)
+			if err != nil {
+				return false, err
+			}
+			if childEmpty {
+				fieldValue.Set(reflect.Zero(fieldValue.Type()))
+			} else {
+				empty = false
+			}
+			continue
+		}
+
+		if fieldValue.Kind() == reflect.Map {
+			for _, key := range fieldValue.MapKeys() {
+				entryEmpty, err := pruneDataType(fieldValue.MapIndex(key), fieldSchema, dataType)
+				if err != nil {
+					return false, err
+				}
+				if entryEmpty {
+					fieldValue.SetMapIndex(key, reflect.Value{})
+				}
+			}
+			if fieldValue.Len() == 0 {
+				fieldValue.Set(reflect.Zero(fieldValue.Type()))
+			} else {
+				empty = false
+			}
+			continue
+		}
+
+		if isListKeyField(field, keys) {
+			// Key does not make list entry non-empty, but it has to stay
+			continue
+		}
+		if matchesDataType(fieldSchema, dataType) {
+			empty = false
+		} else {
+			fieldValue.Set(reflect.Zero(fieldValue.Type()))
+		}
+	}
+
+	return empty, nil
+}
+
+// isZeroLeaf checks if field of GoStruct is not set.
+func isZeroLeaf(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+		return v.IsNil()
+	case reflect.Int64:
+		// Enumeration is unset
+		return v.Int() == 0
+	default:
+		return false
+	}
+}
+
+// isListKeyField checks if any of compressed paths of field points directly to the key of list.
+func isListKeyField(field reflect.StructField, keys map[string]bool) bool {
+	for _, path := range strings.Split(field.Tag.Get("path"), "|") {
+		if keys[path] {
+			return true
+		}
+	}
+
+	return false
+}
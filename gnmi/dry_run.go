@@ -0,0 +1,69 @@
+package gnmi
+
+import (
+	"strings"
+
+	"github.com/openconfig/ygot/ygot"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+	ext "github.com/openconfig/gnmi/proto/gnmi_ext"
+)
+
+// DryRunResult is returned by ConfigCallback in ApplyResult, when changes have
+// been validated but they have not been applied to the device. Set succeeds without changing
+// config and returns the plan of changes in extension of response.
+type DryRunResult struct {
+	Commands    []DryRunCommand // Commands in order they would be executed
+	ChangePaths [][]string      // Paths of changes in changelog, i.e. GoStruct field names and keys of lists
+	Diff        string          // Diff of running config with candidate config
+}
+
+// DryRunCommand is command, which would be executed to apply changes.
+type DryRunCommand struct {
+	Name  string `json:"name"`
+	Phase uint16 `json:"phase"` // Ordinal number of action in transaction
+}
+
+// DryRunPlan is the plan of changes returned to the client in experimental
+// extension of type ExperimentalExtensionDryRunPlan.
+type DryRunPlan struct {
+	Commands []DryRunCommand `json:"commands"`
+	Paths    []string        `json:"paths"` // gNMI paths of changed nodes
+	Diff     string          `json:"diff"`
+}
+
+// dryRunResponse returns response of Set, which changes have been validated
+// only. Paths of changes are resolved in candidate config.
+func (s *Server) dryRunResponse(req *pb.SetRequest, results []*pb.UpdateResult, result *DryRunResult, candidate ygot.GoStruct) (*pb.SetResponse, error) {
+	plan := &DryRunPlan{
+		Commands: result.Commands,
+		Paths:    make([]string, 0, len(result.ChangePaths)),
+		Diff:     result.Diff,
+	}
+	added := make(map[string]bool)
+	for _, changePath := range result.ChangePaths {
+		path := strings.Join(changePath, "/")
+		if elems, _, _, err := s.model.resolveChangePath(candidate, changePath); err == nil {
+			if p, err := ygot.PathToString(&pb.Path{Elem: elems}); err == nil {
+				path = p
+			}
+		}
+		if !added[path] {
+			added[path] = true
+			plan.Paths = append(plan.Paths, path)
+		}
+	}
+
+	planExt, err := newExperimentalExtension(ExperimentalExtensionDryRunPlan, plan)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error in marshaling plan of dry run: %v", err)
+	}
+
+	return &pb.SetResponse{
+		Prefix:    req.GetPrefix(),
+		Response:  results,
+		Extension: []*ext.Extension{planExt},
+	}, nil
+}
@@ -0,0 +1,71 @@
+package gnmi
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/openconfig/goyang/pkg/yang"
+	"github.com/openconfig/ygot/ygot"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// protoLeafUpdates walks GoStruct node placed at fullPath and returns update
+// with native typed value for every leaf set in node. Paths of updates are
+// relative to the first prefixLen elements of fullPath.
+func (m *Model) protoLeafUpdates(fullPath *pb.Path, prefixLen int, schema *yang.Entry, node ygot.GoStruct) ([]*pb.Update, error) {
+	schema, base, err := findStructSchema(schema, fullPath.GetElem(), node)
+	if err != nil {
+		return nil, err
+	}
+	updates, err := m.appendLeafUpdates(nil, base, schema, reflect.ValueOf(node))
+	if err != nil {
+		return nil, err
+	}
+
+	var filtered []*pb.Update
+	for _, update := range updates {
+		elems := update.GetPath().GetElem()
+		if len(elems) < len(fullPath.GetElem()) || !pathsOverlap(elems, fullPath.GetElem()) {
+			continue
+		}
+		update.Path = &pb.Path{Elem: elems[prefixLen:]}
+		filtered = append(filtered, update)
+	}
+
+	return filtered, nil
+}
+
+// findStructSchema returns schema of GoStruct node found at path elems, together
+// with path of that schema. Node of path pointing to container omitted from
+// compressed GoStruct is its parent struct, so schema of the parent is returned.
+func findStructSchema(schema *yang.Entry, elems []*pb.PathElem, node ygot.GoStruct) (*yang.Entry, []*pb.PathElem, error) {
+	for !isStructSchema(schema, node) {
+		if schema.Parent == nil {
+			return nil, nil, fmt.Errorf("schema of %T not found", node)
+		}
+		schema = schema.Parent
+		if len(elems) > 0 {
+			elems = elems[:len(elems)-1]
+		}
+	}
+
+	return schema, elems, nil
+}
+
+// isStructSchema checks if schema describes GoStruct node, i.e. all fields of
+// node can be found in schema.
+func isStructSchema(schema *yang.Entry, node ygot.GoStruct) bool {
+	structType := reflect.TypeOf(node).Elem()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !isSchemaField(field) {
+			continue
+		}
+		if _, _, err := appendSchemaPathElems(nil, schema, field); err != nil {
+			return false
+		}
+	}
+
+	return true
+}
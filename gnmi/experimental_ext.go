@@ -0,0 +1,77 @@
+package gnmi
+
+import (
+	"encoding/json"
+	"fmt"
+
+	ext "github.com/openconfig/gnmi/proto/gnmi_ext"
+)
+
+// ExperimentalExtensionID is ID of the registered extension, which carries
+// JSON encoded ExperimentalExtension. It is the only ID reserved for private
+// use, so all extensions of the device share it and their messages are told
+// apart by type of the envelope.
+const ExperimentalExtensionID = ext.ExtensionID_EID_EXPERIMENTAL
+
+// ExperimentalExtensionType is the type of message carried by experimental
+// extension.
+type ExperimentalExtensionType string
+
+const (
+	// ExperimentalExtensionDryRunPlan is the type of DryRunPlan carried by
+	// SetResponse.
+	ExperimentalExtensionDryRunPlan ExperimentalExtensionType = "dry-run-plan"
+	// ExperimentalExtensionCandidateRequest is the type of CandidateRequest
+	// carried by SetRequest.
+	ExperimentalExtensionCandidateRequest ExperimentalExtensionType = "candidate-request"
+	// ExperimentalExtensionCandidateDiff is the type of CandidateDiff carried
+	// by SetResponse.
+	ExperimentalExtensionCandidateDiff ExperimentalExtensionType = "candidate-diff"
+	// ExperimentalExtensionBestEffortReport is the type of BestEffortResult
+	// carried by SetResponse.
+	ExperimentalExtensionBestEffortReport ExperimentalExtensionType = "best-effort-report"
+)
+
+// ExperimentalExtension is the envelope of message carried by experimental
+// extension.
+type ExperimentalExtension struct {
+	Type ExperimentalExtensionType `json:"type"`
+	Msg  json.RawMessage           `json:"msg"`
+}
+
+// newExperimentalExtension returns experimental extension carrying JSON
+// encoded msg of given type.
+func newExperimentalExtension(typ ExperimentalExtensionType, msg interface{}) (*ext.Extension, error) {
+	rawMsg, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	envelope, err := json.Marshal(&ExperimentalExtension{Type: typ, Msg: rawMsg})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ext.Extension{
+		Ext: &ext.Extension_RegisteredExt{
+			RegisteredExt: &ext.RegisteredExtension{Id: ExperimentalExtensionID, Msg: envelope},
+		},
+	}, nil
+}
+
+// experimentalExtensionMsg returns JSON encoded message of given type carried
+// by extension, or nil if extension carries other one.
+func experimentalExtensionMsg(e *ext.Extension, typ ExperimentalExtensionType) (json.RawMessage, error) {
+	regExt := e.GetRegisteredExt()
+	if regExt == nil || regExt.GetId() != ExperimentalExtensionID {
+		return nil, nil
+	}
+	var envelope ExperimentalExtension
+	if err := json.Unmarshal(regExt.GetMsg(), &envelope); err != nil {
+		return nil, fmt.Errorf("error in parsing envelope of experimental extension: %v", err)
+	}
+	if envelope.Type != typ {
+		return nil, nil
+	}
+
+	return envelope.Msg, nil
+}
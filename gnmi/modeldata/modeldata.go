@@ -22,7 +22,8 @@ limitations under the License.
 //	openconfig-lacp 1.1.1,
 //	openconfig-lldp 0.2.1,
 //	openconfig-platform-transceiver 0.7.0,
-//  openconfig-spanning-tree 0.3.1.
+//  openconfig-spanning-tree 0.3.1,
+//	openconfig-management 1.6.0.
 package modeldata
 
 import (
@@ -48,6 +49,8 @@ const (
 	OpenconfigPlatformTransceiverModel = "openconfig-platform-transceiver"
 	// OpenconfigSTPModel is the openconfig YANG model for STP.
 	OpenconfigSTPModel = "openconfig-spanning-tree"
+	// OpenconfigManagementModel is the YANG model for management of config transactions.
+	OpenconfigManagementModel = "openconfig-management"
 )
 
 var (
@@ -88,5 +91,16 @@ var (
 		Name:         OpenconfigSTPModel,
 		Organization: "OpenConfig working group",
 		Version:      "0.3.1",
+	}, {
+		Name:         OpenconfigManagementModel,
+		Organization: "OpenConfig working group",
+		Version:      "1.6.0",
 	}}
+
+	// ImportedModules maps modules, which are not listed in ModelData, but are
+	// imported by one of supported models, onto that model.
+	ImportedModules = map[string]string{
+		"openconfig-platform":      OpenconfigPlatformTransceiverModel,
+		"openconfig-platform-port": OpenconfigPlatformTransceiverModel,
+	}
 )
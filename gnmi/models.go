@@ -0,0 +1,243 @@
+package gnmi
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	log "github.com/golang/glog"
+	"github.com/openconfig/ygot/ygot"
+	"github.com/r3labs/diff"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"opennos-mgmt/gnmi/modeldata"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// isSupportedModel checks if model of given name is supported by the server.
+func (m *Model) isSupportedModel(name string) bool {
+	for _, model := range m.modelData {
+		if model.GetName() == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fieldModel returns name of supported model, which GoStruct field belongs to.
+// Module not supported on its own, i.e. imported module augmenting the tree,
+// belongs to the model inherited from the closest ancestor.
+func (m *Model) fieldModel(field reflect.StructField, inherited string) string {
+	model := field.Tag.Get("module")
+	if imported, ok := modeldata.ImportedModules[model]; ok {
+		model = imported
+	}
+	if m.isSupportedModel(model) {
+		return model
+	}
+
+	return inherited
+}
+
+// pathModel returns name of supported model, which node pointed by path belongs
+// to. Empty name is returned if path does not belong to any supported model.
+func (m *Model) pathModel(path *pb.Path) string {
+	model := ""
+	nodeType := m.structRootType
+	elems := path.GetElem()
+	for len(elems) > 0 {
+		if nodeType.Kind() != reflect.Ptr || nodeType.Elem().Kind() != reflect.Struct {
+			return model
+		}
+		field, consumed, ok := findFieldByPath(nodeType.Elem(), elems)
+		if !ok {
+			return model
+		}
+		model = m.fieldModel(field, model)
+		elems = elems[consumed:]
+		nodeType = field.Type
+		if nodeType.Kind() == reflect.Map {
+			nodeType = nodeType.Elem()
+		}
+	}
+
+	return model
+}
+
+// changePathModel works like pathModel, but for path of changelog made of
+// GoStruct field names and keys of lists.
+func (m *Model) changePathModel(path []string) string {
+	model := ""
+	nodeType := m.structRootType
+	for i := 0; i < len(path); i++ {
+		if nodeType.Kind() != reflect.Ptr || nodeType.Elem().Kind() != reflect.Struct {
+			return model
+		}
+		field, ok := nodeType.Elem().FieldByName(path[i])
+		if !ok {
+			return model
+		}
+		model = m.fieldModel(field, model)
+		nodeType = field.Type
+		if nodeType.Kind() == reflect.Map {
+			// Skip key of list entry
+			i++
+			nodeType = nodeType.Elem()
+		}
+	}
+
+	return model
+}
+
+// findFieldByPath finds field of GoStruct, which compressed path matches the
+// beginning of elems. Path may end inside of the compressed path, i.e. at
+// container omitted from GoStruct. It returns also number of matched elements.
+func findFieldByPath(structType reflect.Type, elems []*pb.PathElem) (reflect.StructField, int, bool) {
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !isSchemaField(field) {
+			continue
+		}
+		for _, tag := range strings.Split(field.Tag.Get("path"), "|") {
+			tagElems := strings.Split(tag, "/")
+			n := len(tagElems)
+			if len(elems) < n {
+				n = len(elems)
+			}
+			matched := true
+			for j := 0; j < n; j++ {
+				if tagElems[j] != elems[j].GetName() {
+					matched = false
+					break
+				}
+			}
+			if matched {
+				return field, n, true
+			}
+		}
+	}
+
+	return reflect.StructField{}, 0, false
+}
+
+// filterModels returns copy of node with only those nodes set, which belong to
+// allowed models. Model of node itself is given by model.
+func (m *Model) filterModels(node ygot.GoStruct, allowed map[string]bool, model string) (ygot.GoStruct, error) {
+	nodeCopy, err := ygot.DeepCopy(node)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy node: %v", err)
+	}
+	m.pruneModels(reflect.ValueOf(nodeCopy), allowed, model)
+
+	return nodeCopy, nil
+}
+
+// pruneModels clears fields of GoStruct pointed by node, which do not belong
+// to allowed models. It returns true if there is nothing left, besides keys.
+func (m *Model) pruneModels(node reflect.Value, allowed map[string]bool, model string) bool {
+	empty := true
+	structValue := node.Elem()
+	keys := structListKeys(structValue.Type())
+	for i := 0; i < structValue.NumField(); i++ {
+		field := structValue.Type().Field(i)
+		fieldValue := structValue.Field(i)
+		if !isSchemaField(field) || isZeroLeaf(fieldValue) {
+			continue
+		}
+		fieldModel := m.fieldModel(field, model)
+		if fieldModel != "" && !allowed[fieldModel] {
+			fieldValue.Set(reflect.Zero(fieldValue.Type()))
+			continue
+		}
+
+		if _, ok := fieldValue.Interface().(ygot.GoStruct); ok {
+			if m.pruneModels(fieldValue, allowed, fieldModel) {
+				fieldValue.Set(reflect.Zero(fieldValue.Type()))
+			} else {
+				empty = false
+			}
+			continue
+		}
+
+		if fieldValue.Kind() == reflect.Map {
+			for _, key := range fieldValue.MapKeys() {
+				if m.pruneModels(fieldValue.MapIndex(key), allowed, fieldModel) {
+					fieldValue.SetMapIndex(key, reflect.Value{})
+				}
+			}
+			if fieldValue.Len() == 0 {
+				fieldValue.Set(reflect.Zero(fieldValue.Type()))
+			} else {
+				empty = false
+			}
+			continue
+		}
+
+		if isListKeyField(field, keys) {
+			// Key does not make list entry non-empty, but it has to stay
+			continue
+		}
+		if fieldModel == "" {
+			fieldValue.Set(reflect.Zero(fieldValue.Type()))
+		} else {
+			empty = false
+		}
+	}
+
+	return empty
+}
+
+// structListKeys returns names of keys of list entry represented by struct
+// type. In compressed GoStruct key is the only leaf having, besides the path in
+// config container, a path pointing directly to the leaf of list entry.
+func structListKeys(structType reflect.Type) map[string]bool {
+	keys := make(map[string]bool)
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !isSchemaField(field) {
+			continue
+		}
+		tags := strings.Split(field.Tag.Get("path"), "|")
+		if len(tags) < 2 {
+			continue
+		}
+		for _, tag := range tags {
+			if !strings.Contains(tag, "/") {
+				keys[tag] = true
+			}
+		}
+	}
+
+	return keys
+}
+
+// checkChangedModels verifies that the change of config into newConfig touches
+// only nodes belonging to models supported by the server. Caller must hold s.mu.
+func (s *Server) checkChangedModels(newConfig ygot.GoStruct) error {
+	changelog, err := diff.Diff(s.config, newConfig)
+	if err != nil {
+		msg := fmt.Sprintf("error in getting diff of config change: %v", err)
+		log.Error(msg)
+		return status.Error(codes.Internal, msg)
+	}
+	for _, change := range changelog {
+		if s.model.changePathModel(change.Path) == "" {
+			return status.Errorf(codes.InvalidArgument, "change of %s does not belong to any supported model", strings.Join(change.Path, "/"))
+		}
+	}
+
+	return nil
+}
+
+// useModelNames returns set of model names from use_models of request.
+func useModelNames(useModels []*pb.ModelData) map[string]bool {
+	names := make(map[string]bool)
+	for _, model := range useModels {
+		names[model.GetName()] = true
+	}
+
+	return names
+}
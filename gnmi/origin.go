@@ -0,0 +1,130 @@
+package gnmi
+
+import (
+	"github.com/openconfig/ygot/ygot"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+const (
+	// openconfigOrigin is the origin of paths of OpenConfig models. Path
+	// without origin belongs to it as well.
+	openconfigOrigin = "openconfig"
+	// cliOrigin is the origin of native CLI config. The device has no
+	// translator of CLI text, so paths with this origin are rejected as
+	// unimplemented.
+	cliOrigin = "cli"
+)
+
+var (
+	// unionReplacePreservedPaths lists config nodes, which are not managed by
+	// clients, so they are kept untouched by union replace.
+	unionReplacePreservedPaths = []*pb.Path{
+		{Elem: []*pb.PathElem{{Name: "management"}, {Name: "transaction"}}},
+		{Elem: []*pb.PathElem{{Name: "management"}, {Name: "commits"}}},
+		{Elem: []*pb.PathElem{{Name: "management"}, {Name: "scheduled-commits"}}},
+	}
+)
+
+// pathOrigin returns origin of path resolved against prefix. Empty origin
+// means the "openconfig" one.
+func pathOrigin(prefix, path *pb.Path) (string, error) {
+	origin := path.GetOrigin()
+	if prefix.GetOrigin() != "" {
+		if origin != "" && origin != prefix.GetOrigin() {
+			return "", status.Errorf(codes.InvalidArgument, "origin %q of path conflicts with origin %q of prefix", origin, prefix.GetOrigin())
+		}
+		origin = prefix.GetOrigin()
+	}
+	switch origin {
+	case "", openconfigOrigin:
+		return openconfigOrigin, nil
+	case cliOrigin:
+		return cliOrigin, nil
+	default:
+		return "", status.Errorf(codes.InvalidArgument, "unsupported origin %q", origin)
+	}
+}
+
+// errCLIUnsupported returns the error of paths with "cli" origin, as there is
+// no translator of native CLI text.
+func errCLIUnsupported() error {
+	return status.Errorf(codes.Unimplemented, "origin %q is not supported by the device: CLI text cannot be translated to config", cliOrigin)
+}
+
+// doUnionReplace replaces the whole config of the device with union of the
+// updates, which may come from different origins. Nodes listed in
+// unionReplacePreservedPaths are kept as they are in the current config.
+func (s *Server) doUnionReplace(jsonTree map[string]interface{}, prefix *pb.Path, updates []*pb.Update) (ygot.ValidatedGoStruct, error) {
+	unionTree := make(map[string]interface{})
+	for _, upd := range updates {
+		origin, err := pathOrigin(prefix, upd.GetPath())
+		if err != nil {
+			return nil, err
+		}
+		if origin == cliOrigin {
+			return nil, errCLIUnsupported()
+		}
+		if _, err := s.doReplaceOrUpdate(unionTree, pb.UpdateResult_REPLACE, prefix, upd.GetPath(), upd.GetVal()); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, path := range unionReplacePreservedPaths {
+		copyJSONSubtree(unionTree, jsonTree, path)
+	}
+	for k := range jsonTree {
+		delete(jsonTree, k)
+	}
+	for k, v := range unionTree {
+		jsonTree[k] = v
+	}
+
+	newConfig, err := s.toGoStruct(jsonTree)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return newConfig, nil
+}
+
+// copyJSONSubtree sets node of dst pointed by path to the node of src at the
+// same path. Node of dst is deleted if there is no such node in src. Path
+// must not contain keyed list entries.
+func copyJSONSubtree(dst, src map[string]interface{}, path *pb.Path) {
+	elems := path.GetElem()
+	if len(elems) == 0 {
+		return
+	}
+	srcNode := src
+	for _, elem := range elems[:len(elems)-1] {
+		next, ok := srcNode[elem.GetName()].(map[string]interface{})
+		if !ok {
+			srcNode = nil
+			break
+		}
+		srcNode = next
+	}
+	last := elems[len(elems)-1].GetName()
+	srcVal, found := srcNode[last]
+
+	dstNode := dst
+	for _, elem := range elems[:len(elems)-1] {
+		next, ok := dstNode[elem.GetName()].(map[string]interface{})
+		if !ok {
+			if !found {
+				return
+			}
+			next = make(map[string]interface{})
+			dstNode[elem.GetName()] = next
+		}
+		dstNode = next
+	}
+	if found {
+		dstNode[last] = srcVal
+	} else {
+		delete(dstNode, last)
+	}
+}
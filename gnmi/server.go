@@ -36,6 +36,7 @@ import (
 	"github.com/openconfig/gnmi/value"
 	"github.com/openconfig/ygot/experimental/ygotutils"
 	"github.com/openconfig/ygot/ygot"
+	"github.com/r3labs/diff"
 
 	dpb "github.com/golang/protobuf/protoc-gen-go/descriptor"
 	pb "github.com/openconfig/gnmi/proto/gnmi"
@@ -43,14 +44,23 @@ import (
 )
 
 // ConfigCallback is the signature of the function to apply a validated config to the physical device.
-type ConfigCallback func(ygot.ValidatedGoStruct, interface{}) error
+// When the callback is set, its owner is responsible for notifying ON_CHANGE
+// subscribers about applied changes with PublishChangelog.
+type ConfigCallback func(ygot.ValidatedGoStruct, interface{}) (*ApplyResult, error)
+
+// ApplyResult is returned by ConfigCallback, when changes have not been simply
+// applied to the device. Nil result means changes have been applied as a whole.
+type ApplyResult struct {
+	DryRun     *DryRunResult     // Set if changes have been validated only
+	BestEffort *BestEffortResult // Set if changes have been applied partially
+}
 
 var (
 	pbRootPath         = &pb.Path{}
-	supportedEncodings = []pb.Encoding{pb.Encoding_JSON, pb.Encoding_JSON_IETF}
+	supportedEncodings = []pb.Encoding{pb.Encoding_JSON, pb.Encoding_JSON_IETF, pb.Encoding_PROTO}
 )
 
-// Server struct maintains the data structure for device config and implements the interface of gnmi server. It supports Capabilities, Get, and Set APIs.
+// Server struct maintains the data structure for device config and implements the interface of gnmi server. It supports Capabilities, Get, Set and Subscribe APIs.
 // Typical usage:
 //	g := grpc.NewServer()
 //	s, err := Server.NewServer(model, config, callback)
@@ -62,7 +72,7 @@ var (
 // For a real device, apply the config changes to the hardware in the callback function.
 // Arguments:
 //		newConfig: new root config to be applied on the device.
-// func callback(newConfig ygot.ValidatedGoStruct) error {
+// func callback(newConfig ygot.ValidatedGoStruct, cbUserData interface{}) (*ApplyResult, error) {
 //		// Apply the config to your device and return nil if success. return error if fails.
 //		//
 //		// Do something ...
@@ -73,6 +83,16 @@ type Server struct {
 	cbUserData interface{}
 	config     ygot.ValidatedGoStruct
 	mu         sync.RWMutex // mu is the RW lock to protect the access to config
+
+	commitCallback CommitCallback // commitCallback applies requests of commit-confirmed extension
+	pendingCommit  *pendingCommit // pendingCommit is the commit awaiting confirmation
+
+	candidates    map[candidateKey]*namedCandidate // private named candidates of users
+	requestUser   RequestUserFunc                  // requestUser identifies owner of candidates
+	candidateDiff CandidateDiffCallback            // candidateDiff compares candidate with config of the device
+
+	subscribers map[*subscriber]struct{} // active STREAM subscriptions
+	subMu       sync.RWMutex             // subMu protects the access to subscribers
 }
 
 // NewServer creates an instance of Server with given json config.
@@ -86,9 +106,13 @@ func NewServer(model *Model, config []byte, callback ConfigCallback, cbUserData
 		config:     rootStruct,
 		callback:   callback,
 		cbUserData: cbUserData,
+
+		candidates: make(map[candidateKey]*namedCandidate),
+
+		subscribers: make(map[*subscriber]struct{}),
 	}
 	if config != nil && s.callback != nil {
-		if err := s.callback(rootStruct, cbUserData); err != nil {
+		if _, err := s.callback(rootStruct, cbUserData); err != nil {
 			return nil, err
 		}
 	}
@@ -260,6 +284,20 @@ func (s *Server) doReplaceOrUpdate(jsonTree map[string]interface{}, op pb.Update
 	return newConfig, nil
 }
 
+// doOriginReplaceOrUpdate dispatches the replace or update operation by the
+// origin of path.
+func (s *Server) doOriginReplaceOrUpdate(jsonTree map[string]interface{}, op pb.UpdateResult_Operation, prefix, path *pb.Path, val *pb.TypedValue) (ygot.ValidatedGoStruct, error) {
+	origin, err := pathOrigin(prefix, path)
+	if err != nil {
+		return nil, err
+	}
+	if origin == cliOrigin {
+		return nil, errCLIUnsupported()
+	}
+
+	return s.doReplaceOrUpdate(jsonTree, op, prefix, path, val)
+}
+
 func (s *Server) toGoStruct(jsonTree map[string]interface{}) (ygot.ValidatedGoStruct, error) {
 	jsonDump, err := json.Marshal(jsonTree)
 	if err != nil {
@@ -505,144 +543,275 @@ func getUnderlyingTypeValue(v interface{}) (*pb.TypedValue, error) {
 	return inspectUnderlyingTypeValue(reflect.ValueOf(v))
 }
 
-// Get implements the Get RPC in gNMI spec.
-func (s *Server) Get(ctx context.Context, req *pb.GetRequest) (*pb.GetResponse, error) {
-	if req.GetType() != pb.GetRequest_ALL {
-		return nil, status.Errorf(codes.Unimplemented, "unsupported request type: %s", pb.GetRequest_DataType_name[int32(req.GetType())])
-	}
-	if err := s.checkEncodingAndModel(req.GetEncoding(), req.GetUseModels()); err != nil {
-		return nil, status.Error(codes.Unimplemented, err.Error())
-	}
-
-	prefix := req.GetPrefix()
-	paths := req.GetPath()
-	notifications := make([]*pb.Notification, len(paths))
-
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	for i, path := range paths {
-		// Get schema node for path from config struct.
-		fullPath := path
-		if prefix != nil {
-			fullPath = gnmiFullPath(prefix, path)
+// leafTypedValue converts value of leaf node from config struct into gNMI typed value.
+func (m *Model) leafTypedValue(node interface{}) (*pb.TypedValue, error) {
+	switch kind := reflect.ValueOf(node).Kind(); kind {
+	case reflect.Ptr, reflect.Interface:
+		if reflect.ValueOf(node).Elem().Kind() == reflect.Struct {
+			// Union leaf is wrapped by struct
+			return getUnderlyingTypeValue(node)
 		}
-		if fullPath.GetElem() == nil && fullPath.GetElement() != nil {
-			return nil, status.Error(codes.Unimplemented, "deprecated path element type is unsupported")
+		val, err := value.FromScalar(reflect.ValueOf(node).Elem().Interface())
+		if err != nil {
+			return nil, fmt.Errorf("does not contain a scalar type value: %v", err)
 		}
-		node, stat := ygotutils.GetNode(s.model.schemaTreeRoot, s.config, fullPath)
-		if isNil(node) || stat.GetCode() != int32(cpb.Code_OK) {
-			return nil, status.Errorf(codes.NotFound, "path %v not found", fullPath)
+		return val, nil
+	case reflect.Int64:
+		enumMap, ok := m.enumData[reflect.TypeOf(node).Name()]
+		if !ok {
+			return nil, fmt.Errorf("not a GoStruct enumeration type")
+		}
+		return &pb.TypedValue{
+			Value: &pb.TypedValue_StringVal{
+				StringVal: enumMap[reflect.ValueOf(node).Int()].Name,
+			},
+		}, nil
+	case reflect.Slice:
+		t := reflect.ValueOf(node)
+		sa := &pb.ScalarArray{Element: make([]*pb.TypedValue, t.Len())}
+		var err error
+		for i := 0; i < t.Len(); i++ {
+			sa.Element[i], err = getUnderlyingTypeValue(t.Index(i).Interface())
+			if err != nil {
+				return nil, fmt.Errorf("has a problem with reflect an element type: %v", err)
+			}
 		}
+		return &pb.TypedValue{
+			Value: &pb.TypedValue_LeaflistVal{
+				LeaflistVal: sa,
+			},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unexpected kind of leaf node type: %v %v", node, kind)
+	}
+}
 
-		ts := time.Now().UnixNano()
+// getNotification builds a notification carrying the value of the node at
+// path (relative to prefix) from config, restricted to dataType.
+// Caller must hold s.mu.
+func (s *Server) getNotification(config ygot.GoStruct, prefix, path *pb.Path, dataType pb.GetRequest_DataType, encoding pb.Encoding, useModels []*pb.ModelData) (*pb.Notification, error) {
+	// Get schema node for path from config struct.
+	if path == nil {
+		path = &pb.Path{}
+	}
+	fullPath := path
+	if prefix != nil {
+		fullPath = gnmiFullPath(prefix, path)
+	}
+	if fullPath.GetElem() == nil && fullPath.GetElement() != nil {
+		return nil, status.Error(codes.Unimplemented, "deprecated path element type is unsupported")
+	}
+	if isWildcardPath(s.model.schemaTreeRoot, fullPath) {
+		return s.getWildcardNotification(config, prefix, fullPath, dataType, encoding, useModels)
+	}
+	node, stat := ygotutils.GetNode(s.model.schemaTreeRoot, config, fullPath)
+	if isNil(node) || stat.GetCode() != int32(cpb.Code_OK) {
+		return nil, status.Errorf(codes.NotFound, "path %v not found", fullPath)
+	}
 
-		nodeStruct, ok := node.(ygot.GoStruct)
-		// Return leaf node.
-		if !ok {
-			var val *pb.TypedValue
-			switch kind := reflect.ValueOf(node).Kind(); kind {
-			case reflect.Ptr, reflect.Interface:
-				var err error
-				val, err = value.FromScalar(reflect.ValueOf(node).Elem().Interface())
-				if err != nil {
-					msg := fmt.Sprintf("leaf node %v does not contain a scalar type value: %v", path, err)
-					log.Error(msg)
-					return nil, status.Error(codes.Internal, msg)
-				}
-			case reflect.Int64:
-				enumMap, ok := s.model.enumData[reflect.TypeOf(node).Name()]
-				if !ok {
-					return nil, status.Error(codes.Internal, "not a GoStruct enumeration type")
-				}
-				val = &pb.TypedValue{
-					Value: &pb.TypedValue_StringVal{
-						StringVal: enumMap[reflect.ValueOf(node).Int()].Name,
-					},
-				}
-			case reflect.Slice:
-				t := reflect.ValueOf(node)
-				sa := &pb.ScalarArray{Element: make([]*pb.TypedValue, t.Len())}
-				var err error
-				for i := 0; i < t.Len(); i++ {
-					sa.Element[i], err = getUnderlyingTypeValue(t.Index(i).Interface())
-					if err != nil {
-						msg := fmt.Sprintf("leaf node %v has a problem with reflect an element type: %v", path, err)
-						log.Error(msg)
-						return nil, status.Error(codes.Internal, msg)
-					}
-				}
-				val = &pb.TypedValue{
-					Value: &pb.TypedValue_LeaflistVal{
-						LeaflistVal: sa,
-					},
-				}
-			default:
-				return nil, status.Errorf(codes.Internal, "unexpected kind of leaf node type: %v %v", node, kind)
-			}
+	ts := time.Now().UnixNano()
 
-			update := &pb.Update{Path: path, Val: val}
-			notifications[i] = &pb.Notification{
-				Timestamp: ts,
-				Prefix:    prefix,
-				Update:    []*pb.Update{update},
-			}
-			continue
-		}
+	schema := schemaForPath(s.model.schemaTreeRoot, fullPath)
+	if schema == nil {
+		return nil, status.Errorf(codes.NotFound, "schema of path %v not found", fullPath)
+	}
+	nodeModel := s.model.pathModel(fullPath)
+	if len(useModels) > 0 && nodeModel != "" && !useModelNames(useModels)[nodeModel] {
+		return nil, status.Errorf(codes.NotFound, "path %v does not belong to requested models", fullPath)
+	}
 
-		if req.GetUseModels() != nil {
-			return nil, status.Errorf(codes.Unimplemented, "filtering Get using use_models is unsupported, got: %v", req.GetUseModels())
+	nodeStruct, ok := node.(ygot.GoStruct)
+	// Return leaf node.
+	if !ok {
+		if !matchesDataType(schema, dataType) {
+			return nil, status.Errorf(codes.NotFound, "path %v not found for data type %v", fullPath, dataType)
 		}
-
-		// Return IETF JSON by default.
-		jsonEncoder := func() (map[string]interface{}, error) {
-			return ygot.ConstructIETFJSON(nodeStruct, &ygot.RFC7951JSONConfig{AppendModuleName: true})
+		if len(useModels) > 0 && nodeModel == "" {
+			return nil, status.Errorf(codes.NotFound, "path %v does not belong to requested models", fullPath)
 		}
-		jsonType := "IETF"
-		buildUpdate := func(b []byte) *pb.Update {
-			return &pb.Update{Path: path, Val: &pb.TypedValue{Value: &pb.TypedValue_JsonIetfVal{JsonIetfVal: b}}}
+		val, err := s.model.leafTypedValue(node)
+		if err != nil {
+			msg := fmt.Sprintf("leaf node %v: %v", path, err)
+			log.Error(msg)
+			return nil, status.Error(codes.Internal, msg)
 		}
 
-		if req.GetEncoding() == pb.Encoding_JSON {
-			jsonEncoder = func() (map[string]interface{}, error) {
-				return ygot.ConstructInternalJSON(nodeStruct)
-			}
-			jsonType = "Internal"
-			buildUpdate = func(b []byte) *pb.Update {
-				return &pb.Update{Path: path, Val: &pb.TypedValue{Value: &pb.TypedValue_JsonVal{JsonVal: b}}}
-			}
-		}
+		update := &pb.Update{Path: path, Val: val}
+		return &pb.Notification{
+			Timestamp: ts,
+			Prefix:    prefix,
+			Update:    []*pb.Update{update},
+		}, nil
+	}
 
-		jsonTree, err := jsonEncoder()
-		if err != nil {
-			msg := fmt.Sprintf("error in constructing %s JSON tree from requested node: %v", jsonType, err)
+	nodeStruct, err := filterDataType(nodeStruct, schema, dataType)
+	if err != nil {
+		msg := fmt.Sprintf("error in filtering %v data of requested node: %v", dataType, err)
+		log.Error(msg)
+		return nil, status.Error(codes.Internal, msg)
+	}
+	if len(useModels) > 0 {
+		if nodeStruct, err = s.model.filterModels(nodeStruct, useModelNames(useModels), nodeModel); err != nil {
+			msg := fmt.Sprintf("error in filtering requested node by models: %v", err)
 			log.Error(msg)
 			return nil, status.Error(codes.Internal, msg)
 		}
+	}
 
-		jsonDump, err := json.Marshal(jsonTree)
+	if encoding == pb.Encoding_PROTO {
+		updates, err := s.model.protoLeafUpdates(fullPath, len(fullPath.GetElem())-len(path.GetElem()), schema, nodeStruct)
 		if err != nil {
-			msg := fmt.Sprintf("error in marshaling %s JSON tree to bytes: %v", jsonType, err)
+			msg := fmt.Sprintf("error in encoding leaves of requested node: %v", err)
 			log.Error(msg)
 			return nil, status.Error(codes.Internal, msg)
 		}
-
-		update := buildUpdate(jsonDump)
-		notifications[i] = &pb.Notification{
+		return &pb.Notification{
 			Timestamp: ts,
 			Prefix:    prefix,
-			Update:    []*pb.Update{update},
+			Update:    updates,
+		}, nil
+	}
+
+	// Return IETF JSON by default.
+	jsonEncoder := func() (map[string]interface{}, error) {
+		return ygot.ConstructIETFJSON(nodeStruct, &ygot.RFC7951JSONConfig{AppendModuleName: true})
+	}
+	jsonType := "IETF"
+	buildUpdate := func(b []byte) *pb.Update {
+		return &pb.Update{Path: path, Val: &pb.TypedValue{Value: &pb.TypedValue_JsonIetfVal{JsonIetfVal: b}}}
+	}
+
+	if encoding == pb.Encoding_JSON {
+		jsonEncoder = func() (map[string]interface{}, error) {
+			return ygot.ConstructInternalJSON(nodeStruct)
+		}
+		jsonType = "Internal"
+		buildUpdate = func(b []byte) *pb.Update {
+			return &pb.Update{Path: path, Val: &pb.TypedValue{Value: &pb.TypedValue_JsonVal{JsonVal: b}}}
+		}
+	}
+
+	jsonTree, err := jsonEncoder()
+	if err != nil {
+		msg := fmt.Sprintf("error in constructing %s JSON tree from requested node: %v", jsonType, err)
+		log.Error(msg)
+		return nil, status.Error(codes.Internal, msg)
+	}
+
+	jsonDump, err := json.Marshal(jsonTree)
+	if err != nil {
+		msg := fmt.Sprintf("error in marshaling %s JSON tree to bytes: %v", jsonType, err)
+		log.Error(msg)
+		return nil, status.Error(codes.Internal, msg)
+	}
+
+	update := buildUpdate(jsonDump)
+	return &pb.Notification{
+		Timestamp: ts,
+		Prefix:    prefix,
+		Update:    []*pb.Update{update},
+	}, nil
+}
+
+// getWildcardNotification builds a notification with a separate update for
+// every node matching fullPath with wildcards. Paths of updates are fully
+// resolved and relative to prefix, unless prefix contains wildcards itself.
+// Caller must hold s.mu.
+func (s *Server) getWildcardNotification(config ygot.GoStruct, prefix, fullPath *pb.Path, dataType pb.GetRequest_DataType, encoding pb.Encoding, useModels []*pb.ModelData) (*pb.Notification, error) {
+	paths, err := expandWildcardPath(config, s.model.schemaTreeRoot, fullPath)
+	if err != nil {
+		msg := fmt.Sprintf("error in expanding path %v: %v", fullPath, err)
+		log.Error(msg)
+		return nil, status.Error(codes.Internal, msg)
+	}
+
+	if isWildcardPath(s.model.schemaTreeRoot, prefix) {
+		prefix = nil
+	}
+	notification := &pb.Notification{Timestamp: time.Now().UnixNano(), Prefix: prefix}
+	for _, path := range paths {
+		n, err := s.getNotification(config, nil, path, dataType, encoding, useModels)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				continue
+			}
+			return nil, err
+		}
+		for _, update := range n.GetUpdate() {
+			update.Path = &pb.Path{Elem: update.GetPath().GetElem()[len(prefix.GetElem()):]}
+			notification.Update = append(notification.Update, update)
 		}
 	}
+	if len(notification.GetUpdate()) == 0 {
+		return nil, status.Errorf(codes.NotFound, "path %v not found", fullPath)
+	}
+
+	return notification, nil
+}
+
+// Get implements the Get RPC in gNMI spec.
+func (s *Server) Get(ctx context.Context, req *pb.GetRequest) (*pb.GetResponse, error) {
+	if _, ok := pb.GetRequest_DataType_name[int32(req.GetType())]; !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported request type: %v", req.GetType())
+	}
+	if err := s.checkEncodingAndModel(req.GetEncoding(), req.GetUseModels()); err != nil {
+		return nil, status.Error(codes.Unimplemented, err.Error())
+	}
+
+	prefix := req.GetPrefix()
+	paths := req.GetPath()
+	notifications := make([]*pb.Notification, len(paths))
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	config, grpcStatusError := s.targetConfig(ctx, prefix)
+	if grpcStatusError != nil {
+		return nil, grpcStatusError
+	}
+	for i, path := range paths {
+		notification, err := s.getNotification(config, prefix, path, req.GetType(), req.GetEncoding(), req.GetUseModels())
+		if err != nil {
+			return nil, err
+		}
+		notifications[i] = notification
+	}
 
 	return &pb.GetResponse{Notification: notifications}, nil
 }
 
-// Set implements the Set RPC in gNMI spec.
+// Set implements the Set RPC in gNMI spec. All operations of the request are
+// applied to a private copy of the config tree, so either all of them take
+// effect or, in case of any failure, the config is left intact.
 func (s *Server) Set(ctx context.Context, req *pb.SetRequest) (*pb.SetResponse, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	name, grpcStatusError := candidateName(req.GetPrefix())
+	if grpcStatusError != nil {
+		return nil, grpcStatusError
+	}
+	if name != "" {
+		return s.setCandidate(ctx, name, req)
+	}
+
+	commitReq, grpcStatusError := commitRequest(req.GetExtension())
+	if grpcStatusError != nil {
+		return nil, grpcStatusError
+	}
+	if commitReq != nil {
+		if grpcStatusError := s.checkCommitRequest(commitReq, req); grpcStatusError != nil {
+			return nil, grpcStatusError
+		}
+		if commitReq.Action != CommitActionCommit {
+			if grpcStatusError := s.doCommitAction(commitReq); grpcStatusError != nil {
+				return nil, grpcStatusError
+			}
+			return &pb.SetResponse{Prefix: req.GetPrefix()}, nil
+		}
+	}
+
+	// The tree is built from scratch, so changes do not affect s.config
 	jsonTree, err := ygot.ConstructIETFJSON(s.config, &ygot.RFC7951JSONConfig{})
 	if err != nil {
 		msg := fmt.Sprintf("error in constructing IETF JSON tree from config struct: %v", err)
@@ -650,23 +819,50 @@ func (s *Server) Set(ctx context.Context, req *pb.SetRequest) (*pb.SetResponse,
 		return nil, status.Error(codes.Internal, msg)
 	}
 
+	results, changed, grpcStatusError := s.applySetOperations(jsonTree, req)
+	if grpcStatusError != nil {
+		return nil, grpcStatusError
+	}
+	if changed {
+		// Candidate is built once from the final tree of all operations
+		candidate, grpcStatusError := s.newConfigStruct(jsonTree)
+		if grpcStatusError != nil {
+			return nil, grpcStatusError
+		}
+		resp, _, grpcStatusError := s.applyConfig(req, results, candidate, commitReq)
+		return resp, grpcStatusError
+	}
+
+	return &pb.SetResponse{
+		Prefix:   req.GetPrefix(),
+		Response: results,
+	}, nil
+}
+
+// applySetOperations applies delete, replace, update and union_replace
+// operations of the request to jsonTree. It reports whether any operation has
+// been applied.
+func (s *Server) applySetOperations(jsonTree map[string]interface{}, req *pb.SetRequest) ([]*pb.UpdateResult, bool, error) {
 	prefix := req.GetPrefix()
 	var results []*pb.UpdateResult
 
-	var updatedConfig ygot.ValidatedGoStruct = nil
-	updatesSize := len(req.GetDelete())
-	for i, path := range req.GetDelete() {
+	changed := false
+	for _, path := range req.GetDelete() {
+		origin, grpcStatusError := pathOrigin(prefix, path)
+		if grpcStatusError != nil {
+			return nil, false, grpcStatusError
+		}
+		if origin == cliOrigin {
+			return nil, false, errCLIUnsupported()
+		}
 		newConfig, grpcStatusError := s.doDelete(jsonTree, prefix, path)
 		if grpcStatusError != nil {
-			return nil, grpcStatusError
+			return nil, false, grpcStatusError
 		}
 
 		// Means that there wasn't any delete request
 		if newConfig != nil {
-			if i+1 == updatesSize {
-				updatedConfig = newConfig
-			}
-
+			changed = true
 			res := &pb.UpdateResult{
 				Path: path,
 				Op:   pb.UpdateResult_DELETE,
@@ -674,76 +870,129 @@ func (s *Server) Set(ctx context.Context, req *pb.SetRequest) (*pb.SetResponse,
 			results = append(results, res)
 		}
 	}
-	updatesSize = len(req.GetReplace())
-	for i, upd := range req.GetReplace() {
-		newConfig, grpcStatusError := s.doReplaceOrUpdate(jsonTree, pb.UpdateResult_REPLACE, prefix, upd.GetPath(), upd.GetVal())
-		if grpcStatusError != nil {
-			return nil, grpcStatusError
-		}
-
-		if i+1 == updatesSize {
-			updatedConfig = newConfig
+	for _, upd := range req.GetReplace() {
+		if _, grpcStatusError := s.doOriginReplaceOrUpdate(jsonTree, pb.UpdateResult_REPLACE, prefix, upd.GetPath(), upd.GetVal()); grpcStatusError != nil {
+			return nil, false, grpcStatusError
 		}
 
+		changed = true
 		res := &pb.UpdateResult{
 			Path: upd.GetPath(),
 			Op:   pb.UpdateResult_REPLACE,
 		}
 		results = append(results, res)
 	}
-	updatesSize = len(req.GetUpdate())
-	for i, upd := range req.GetUpdate() {
-		newConfig, grpcStatusError := s.doReplaceOrUpdate(jsonTree, pb.UpdateResult_UPDATE, prefix, upd.GetPath(), upd.GetVal())
-		if grpcStatusError != nil {
-			return nil, grpcStatusError
-		}
-
-		if i+1 == updatesSize {
-			updatedConfig = newConfig
+	for _, upd := range req.GetUpdate() {
+		if _, grpcStatusError := s.doOriginReplaceOrUpdate(jsonTree, pb.UpdateResult_UPDATE, prefix, upd.GetPath(), upd.GetVal()); grpcStatusError != nil {
+			return nil, false, grpcStatusError
 		}
 
+		changed = true
 		res := &pb.UpdateResult{
 			Path: upd.GetPath(),
 			Op:   pb.UpdateResult_UPDATE,
 		}
 		results = append(results, res)
 	}
+	if len(req.GetUnionReplace()) > 0 {
+		if _, grpcStatusError := s.doUnionReplace(jsonTree, prefix, req.GetUnionReplace()); grpcStatusError != nil {
+			return nil, false, grpcStatusError
+		}
 
-	if updatedConfig != nil {
-		// Apply the validated operation to the device.
-		if s.callback != nil {
-			if applyErr := s.callback(updatedConfig, s.cbUserData); applyErr != nil {
-				// Rollback is done by transaction mechanism
-				// if rollbackErr := s.callback(s.config, s.cbUserData); rollbackErr != nil {
-				// 	return nil, status.Errorf(codes.Internal, "error in rollback the failed operation (%v): %v", applyErr, rollbackErr)
-				// }
-				return nil, status.Errorf(codes.Aborted, "error in applying operation to device: %v", applyErr)
+		changed = true
+		for _, upd := range req.GetUnionReplace() {
+			res := &pb.UpdateResult{
+				Path: upd.GetPath(),
+				Op:   pb.UpdateResult_REPLACE,
 			}
+			results = append(results, res)
 		}
 	}
 
-	jsonDump, err := json.Marshal(jsonTree)
+	return results, changed, nil
+}
+
+// newConfigStruct builds config struct from jsonTree and checks that all
+// changes belong to supported models.
+func (s *Server) newConfigStruct(jsonTree map[string]interface{}) (ygot.ValidatedGoStruct, error) {
+	config, err := s.toGoStruct(jsonTree)
 	if err != nil {
-		msg := fmt.Sprintf("error in marshaling IETF JSON tree to bytes: %v", err)
-		log.Error(msg)
-		return nil, status.Error(codes.Internal, msg)
+		log.Error(err)
+		return nil, status.Error(codes.Internal, err.Error())
 	}
-	rootStruct, err := s.model.NewConfigStruct(jsonDump)
+	if grpcStatusError := s.checkChangedModels(config); grpcStatusError != nil {
+		return nil, grpcStatusError
+	}
+	return config, nil
+}
+
+// applyConfig applies the validated candidate config to the device and makes
+// it the config of the server. It reports whether the config has been applied,
+// which is not the case of dry run.
+func (s *Server) applyConfig(req *pb.SetRequest, results []*pb.UpdateResult, candidate ygot.ValidatedGoStruct, commitReq *CommitRequest) (*pb.SetResponse, bool, error) {
+	// Apply the validated operation to the device. Rollback of device is
+	// done by transaction mechanism, while s.config has not been touched yet.
+	var bestEffort *BestEffortResult
+	if commitReq != nil {
+		if applyErr := s.commitCallback(commitReq, candidate, s.cbUserData); applyErr != nil {
+			return nil, false, s.applyErrorStatus(applyErr, candidate)
+		}
+		s.pendingCommit = &pendingCommit{id: commitReq.ID, rollbackConfig: s.config}
+	} else if s.callback != nil {
+		applyResult, applyErr := s.callback(candidate, s.cbUserData)
+		if applyErr != nil {
+			return nil, false, s.applyErrorStatus(applyErr, candidate)
+		}
+		if applyResult != nil && applyResult.DryRun != nil {
+			// Dry run leaves config intact
+			resp, err := s.dryRunResponse(req, results, applyResult.DryRun, candidate)
+			return resp, false, err
+		}
+		if applyResult != nil && applyResult.BestEffort != nil {
+			// Candidate keeps changes applied by best-effort commit only
+			log.Infof("%v", applyResult.BestEffort)
+			bestEffort = applyResult.BestEffort
+		}
+	} else {
+		// Without callback nobody else knows about the change, so notify subscribers here
+		if changelog, err := diff.Diff(s.config, candidate); err != nil {
+			log.Errorf("failed to get diff of config change: %v", err)
+		} else {
+			s.PublishChangelog(changelog, candidate)
+		}
+	}
+	// Callback may keep the candidate, so server holds its own copy. It is
+	// taken after callback, which may complete the candidate with state of
+	// the device, e.g. with history of commits.
+	newConfig, err := ygot.DeepCopy(candidate)
 	if err != nil {
-		msg := fmt.Sprintf("error in creating config struct from IETF JSON data: %v", err)
+		msg := fmt.Sprintf("error in copying config struct: %v", err)
 		log.Error(msg)
-		return nil, status.Error(codes.Internal, msg)
+		return nil, false, status.Error(codes.Internal, msg)
+	}
+	s.config = newConfig.(ygot.ValidatedGoStruct)
+
+	if bestEffort != nil {
+		resp, err := bestEffortResponse(req, results, bestEffort)
+		return resp, true, err
 	}
-	s.config = rootStruct
 	return &pb.SetResponse{
 		Prefix:   req.GetPrefix(),
 		Response: results,
-	}, nil
+	}, true, nil
 }
 
-// Subscribe method is not implemented.
-func (s *Server) Subscribe(stream pb.GNMI_SubscribeServer) error {
-	return status.Error(codes.Unimplemented, "Subscribe is not implemented.")
+// applyErrorStatus converts error of applying candidate config to the device
+// into grpc status error. Error, which already carries grpc status, e.g. when
+// config is locked by other session, is returned as it is.
+func (s *Server) applyErrorStatus(applyErr error, candidate ygot.ValidatedGoStruct) error {
+	if changeErr, ok := applyErr.(*ChangeError); ok {
+		return s.model.changeErrorStatus(changeErr, candidate)
+	}
+	if _, ok := status.FromError(applyErr); ok {
+		return applyErr
+	}
+	return status.Errorf(codes.Aborted, "error in applying operation to device: %v", applyErr)
 }
 
 // InternalUpdate is an experimental feature to let the server update its
@@ -17,16 +17,23 @@ package gnmi
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/openconfig/gnmi/value"
 	"github.com/openconfig/ygot/ygot"
+	"golang.org/x/net/context"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 
 	pb "github.com/openconfig/gnmi/proto/gnmi"
+	ext "github.com/openconfig/gnmi/proto/gnmi_ext"
 
 	"opennos-mgmt/gnmi/modeldata"
 	"opennos-mgmt/gnmi/modeldata/oc"
@@ -219,9 +226,303 @@ func TestGet(t *testing.T) {
 	}
 }
 
+func TestGetDataType(t *testing.T) {
+	jsonConfigRoot := `{
+		"openconfig-interfaces:interfaces": {
+			"interface": [
+				{
+					"name": "eth-1",
+					"config": {
+						"name": "eth-1",
+						"mtu": 1500
+					},
+					"state": {
+						"oper-status": "UP"
+					}
+				}
+			]
+		}
+	}`
+
+	s, err := NewServer(model, []byte(jsonConfigRoot), nil, nil)
+	if err != nil {
+		t.Fatalf("error in creating server: %v", err)
+	}
+
+	intfPath := `
+		elem: <name: "interfaces" >
+		elem: <
+			name: "interface"
+			key: <key: "name" value: "eth-1" >
+		>`
+	tds := []struct {
+		desc        string
+		textPbPath  string
+		dataType    pb.GetRequest_DataType
+		wantRetCode codes.Code
+		wantRespVal interface{}
+	}{{
+		desc:        "config leaf of CONFIG type",
+		textPbPath:  intfPath + `elem: <name: "config" > elem: <name: "mtu" >`,
+		dataType:    pb.GetRequest_CONFIG,
+		wantRetCode: codes.OK,
+		wantRespVal: uint64(1500),
+	}, {
+		desc:        "config leaf of STATE type",
+		textPbPath:  intfPath + `elem: <name: "config" > elem: <name: "mtu" >`,
+		dataType:    pb.GetRequest_STATE,
+		wantRetCode: codes.NotFound,
+	}, {
+		desc:        "state leaf of STATE type",
+		textPbPath:  intfPath + `elem: <name: "state" > elem: <name: "oper-status" >`,
+		dataType:    pb.GetRequest_STATE,
+		wantRetCode: codes.OK,
+		wantRespVal: "UP",
+	}, {
+		desc:        "state leaf of OPERATIONAL type",
+		textPbPath:  intfPath + `elem: <name: "state" > elem: <name: "oper-status" >`,
+		dataType:    pb.GetRequest_OPERATIONAL,
+		wantRetCode: codes.OK,
+		wantRespVal: "UP",
+	}, {
+		desc:        "state leaf of CONFIG type",
+		textPbPath:  intfPath + `elem: <name: "state" > elem: <name: "oper-status" >`,
+		dataType:    pb.GetRequest_CONFIG,
+		wantRetCode: codes.NotFound,
+	}, {
+		desc:        "list entry of CONFIG type",
+		textPbPath:  intfPath,
+		dataType:    pb.GetRequest_CONFIG,
+		wantRetCode: codes.OK,
+		wantRespVal: `{
+			"openconfig-interfaces:config": {"name": "eth-1", "mtu": 1500},
+			"openconfig-interfaces:name": "eth-1"
+		}`,
+	}}
+
+	for _, td := range tds {
+		t.Run(td.desc, func(t *testing.T) {
+			runTestGetDataType(t, s, td.textPbPath, td.dataType, td.wantRetCode, td.wantRespVal, nil)
+		})
+	}
+}
+
+func TestGetWildcard(t *testing.T) {
+	jsonConfigRoot := `{
+		"openconfig-interfaces:interfaces": {
+			"interface": [
+				{
+					"name": "eth-1",
+					"config": {
+						"name": "eth-1",
+						"mtu": 1500
+					}
+				},
+				{
+					"name": "eth-2",
+					"config": {
+						"name": "eth-2",
+						"mtu": 9000
+					}
+				}
+			]
+		}
+	}`
+
+	s, err := NewServer(model, []byte(jsonConfigRoot), nil, nil)
+	if err != nil {
+		t.Fatalf("error in creating server: %v", err)
+	}
+
+	tds := []struct {
+		desc        string
+		textPbPath  string
+		wantRetCode codes.Code
+		wantMtus    map[string]uint64
+	}{{
+		desc: "any key value",
+		textPbPath: `
+			elem: <name: "interfaces" >
+			elem: <
+				name: "interface"
+				key: <key: "name" value: "*" >
+			>
+			elem: <name: "config" >
+			elem: <name: "mtu" >`,
+		wantRetCode: codes.OK,
+		wantMtus:    map[string]uint64{"eth-1": 1500, "eth-2": 9000},
+	}, {
+		desc: "missing key",
+		textPbPath: `
+			elem: <name: "interfaces" >
+			elem: <name: "interface" >
+			elem: <name: "*" >
+			elem: <name: "mtu" >`,
+		wantRetCode: codes.OK,
+		wantMtus:    map[string]uint64{"eth-1": 1500, "eth-2": 9000},
+	}, {
+		desc: "any levels",
+		textPbPath: `
+			elem: <name: "..." >
+			elem: <name: "mtu" >`,
+		wantRetCode: codes.OK,
+		wantMtus:    map[string]uint64{"eth-1": 1500, "eth-2": 9000},
+	}, {
+		desc: "no matching key",
+		textPbPath: `
+			elem: <name: "interfaces" >
+			elem: <name: "interface" >
+			elem: <name: "config" >
+			elem: <name: "description" >`,
+		wantRetCode: codes.NotFound,
+	}}
+
+	for _, td := range tds {
+		t.Run(td.desc, func(t *testing.T) {
+			var pbPath pb.Path
+			if err := proto.UnmarshalText(td.textPbPath, &pbPath); err != nil {
+				t.Fatalf("error in unmarshaling path: %v", err)
+			}
+			resp, err := s.Get(nil, &pb.GetRequest{Path: []*pb.Path{&pbPath}, Encoding: pb.Encoding_JSON_IETF})
+			if got := status.Code(err); got != td.wantRetCode {
+				t.Fatalf("got return code %v, want %v", got, td.wantRetCode)
+			}
+			if err != nil {
+				return
+			}
+
+			gotMtus := make(map[string]uint64)
+			for _, update := range resp.GetNotification()[0].GetUpdate() {
+				elems := update.GetPath().GetElem()
+				if len(elems) != 4 || elems[3].GetName() != "mtu" {
+					t.Fatalf("got path %v, want fully resolved path of mtu", update.GetPath())
+				}
+				gotMtus[elems[1].GetKey()["name"]] = update.GetVal().GetUintVal()
+			}
+			if !reflect.DeepEqual(gotMtus, td.wantMtus) {
+				t.Errorf("got MTUs %v, want %v", gotMtus, td.wantMtus)
+			}
+		})
+	}
+}
+
+func TestGetProtoEncoding(t *testing.T) {
+	jsonConfigRoot := `{
+		"openconfig-interfaces:interfaces": {
+			"interface": [
+				{
+					"name": "eth-1",
+					"config": {
+						"name": "eth-1",
+						"mtu": 1500,
+						"enabled": true
+					}
+				}
+			]
+		}
+	}`
+
+	s, err := NewServer(model, []byte(jsonConfigRoot), nil, nil)
+	if err != nil {
+		t.Fatalf("error in creating server: %v", err)
+	}
+
+	var pbPath pb.Path
+	if err := proto.UnmarshalText(`
+		elem: <name: "interfaces" >
+		elem: <
+			name: "interface"
+			key: <key: "name" value: "eth-1" >
+		>
+		elem: <name: "config" >`, &pbPath); err != nil {
+		t.Fatalf("error in unmarshaling path: %v", err)
+	}
+	resp, err := s.Get(nil, &pb.GetRequest{Path: []*pb.Path{&pbPath}, Encoding: pb.Encoding_PROTO})
+	if err != nil {
+		t.Fatalf("got error %v, want nil", err)
+	}
+
+	gotVals := make(map[string]interface{})
+	for _, update := range resp.GetNotification()[0].GetUpdate() {
+		elems := update.GetPath().GetElem()
+		if len(elems) != 4 || elems[2].GetName() != "config" {
+			t.Fatalf("got path %v, want path of leaf in config container", update.GetPath())
+		}
+		if gotVals[elems[3].GetName()], err = value.ToScalar(update.GetVal()); err != nil {
+			t.Fatalf("got %v, want a scalar value: %v", update.GetVal(), err)
+		}
+	}
+	wantVals := map[string]interface{}{"name": "eth-1", "mtu": uint64(1500), "enabled": true}
+	if !reflect.DeepEqual(gotVals, wantVals) {
+		t.Errorf("got leaves %v, want %v", gotVals, wantVals)
+	}
+}
+
+func TestGetUseModels(t *testing.T) {
+	jsonConfigRoot := `{
+		"openconfig-interfaces:interfaces": {
+			"interface": [
+				{
+					"name": "ae-1",
+					"config": {
+						"name": "ae-1",
+						"mtu": 1500
+					}
+				}
+			]
+		},
+		"openconfig-lacp:lacp": {
+			"interfaces": {
+				"interface": [
+					{
+						"name": "ae-1",
+						"config": {
+							"name": "ae-1",
+							"interval": "FAST"
+						}
+					}
+				]
+			}
+		}
+	}`
+
+	s, err := NewServer(model, []byte(jsonConfigRoot), nil, nil)
+	if err != nil {
+		t.Fatalf("error in creating server: %v", err)
+	}
+	var lacpModel []*pb.ModelData
+	for _, m := range model.modelData {
+		if m.GetName() == modeldata.OpenconfigLACPModel {
+			lacpModel = append(lacpModel, m)
+		}
+	}
+
+	resp, err := s.Get(nil, &pb.GetRequest{Path: []*pb.Path{&pb.Path{}}, Encoding: pb.Encoding_JSON_IETF, UseModels: lacpModel})
+	if err != nil {
+		t.Fatalf("got error %v, want nil", err)
+	}
+	var gotTree map[string]interface{}
+	if err := json.Unmarshal(resp.GetNotification()[0].GetUpdate()[0].GetVal().GetJsonIetfVal(), &gotTree); err != nil {
+		t.Fatalf("error in unmarshaling IETF JSON data to json container: %v", err)
+	}
+	if _, ok := gotTree["openconfig-lacp:lacp"]; !ok {
+		t.Errorf("got %v, want lacp tree", gotTree)
+	}
+	if _, ok := gotTree["openconfig-interfaces:interfaces"]; ok {
+		t.Errorf("got %v, want no interfaces tree", gotTree)
+	}
+
+	runTestGet(t, s, `elem: <name: "interfaces" >`, codes.NotFound, nil, lacpModel)
+}
+
 // runTestGet requests a path from the server by Get grpc call, and compares if
 // the return code and response value are expected.
 func runTestGet(t *testing.T, s *Server, textPbPath string, wantRetCode codes.Code, wantRespVal interface{}, useModels []*pb.ModelData) {
+	runTestGetDataType(t, s, textPbPath, pb.GetRequest_ALL, wantRetCode, wantRespVal, useModels)
+}
+
+// runTestGetDataType works like runTestGet, but requests only data of dataType.
+func runTestGetDataType(t *testing.T, s *Server, textPbPath string, dataType pb.GetRequest_DataType, wantRetCode codes.Code, wantRespVal interface{}, useModels []*pb.ModelData) {
 	// Send request
 	var pbPath pb.Path
 	if err := proto.UnmarshalText(textPbPath, &pbPath); err != nil {
@@ -229,6 +530,7 @@ func runTestGet(t *testing.T, s *Server, textPbPath string, wantRetCode codes.Co
 	}
 	req := &pb.GetRequest{
 		Path:      []*pb.Path{&pbPath},
+		Type:      dataType,
 		Encoding:  pb.Encoding_JSON_IETF,
 		UseModels: useModels,
 	}
@@ -1159,3 +1461,756 @@ func runTestSet(t *testing.T, m *Model, tc gnmiSetTestCase) {
 		t.Fatalf("got server config %v\nwant: %v", gotConfigJSON, wantConfigJSON)
 	}
 }
+
+func TestSetChangeError(t *testing.T) {
+	callback := func(config ygot.ValidatedGoStruct, userData interface{}) (*ApplyResult, error) {
+		return nil, &ChangeError{
+			Path:   []string{"System", "Config", "DomainName"},
+			Reason: "UNSUPPORTED",
+			Err:    errors.New("domain name is not supported"),
+		}
+	}
+	s, err := NewServer(model, []byte(`{"system": {"config": {"hostname": "switch_a"}}}`), callback, nil)
+	if err != nil {
+		t.Fatalf("error in creating server: %v", err)
+	}
+
+	req := &pb.SetRequest{Update: []*pb.Update{{
+		Path: &pb.Path{Elem: []*pb.PathElem{{Name: "system"}, {Name: "config"}, {Name: "domain-name"}}},
+		Val:  &pb.TypedValue{Value: &pb.TypedValue_StringVal{StringVal: "foo.bar.com"}},
+	}}}
+	_, err = s.Set(nil, req)
+
+	gotRetStatus, ok := status.FromError(err)
+	if !ok {
+		t.Fatal("got a non-grpc error from grpc call")
+	}
+	if gotRetStatus.Code() != codes.InvalidArgument {
+		t.Fatalf("got return code %v, want %v\nerror message: %v", gotRetStatus.Code(), codes.InvalidArgument, err)
+	}
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, detail := range gotRetStatus.Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			violations = append(violations, badRequest.GetFieldViolations()...)
+		}
+	}
+	if len(violations) != 1 {
+		t.Fatalf("got %d field violations, want 1", len(violations))
+	}
+	if got, want := violations[0].GetField(), "/system/config/domain-name"; got != want {
+		t.Errorf("got violation of field %q, want %q", got, want)
+	}
+	if got, want := violations[0].GetDescription(), "UNSUPPORTED: domain name is not supported"; got != want {
+		t.Errorf("got violation description %q, want %q", got, want)
+	}
+}
+
+func TestSetStatusError(t *testing.T) {
+	callback := func(config ygot.ValidatedGoStruct, userData interface{}) (*ApplyResult, error) {
+		return nil, status.Error(codes.FailedPrecondition, "configuration is locked by other session")
+	}
+	initConfig := `{"interfaces": {"interface": [{"name": "eth-1/1", "config": {"name": "eth-1/1", "description": "uplink"}}]}}`
+	s, err := NewServer(model, []byte(initConfig), callback, nil)
+	if err != nil {
+		t.Fatalf("error in creating server: %v", err)
+	}
+
+	req := &pb.SetRequest{Update: []*pb.Update{{
+		Path: &pb.Path{Elem: []*pb.PathElem{
+			{Name: "interfaces"}, {Name: "interface", Key: map[string]string{"name": "eth-1/1"}}, {Name: "config"}, {Name: "description"},
+		}},
+		Val: &pb.TypedValue{Value: &pb.TypedValue_StringVal{StringVal: "downlink"}},
+	}}}
+	_, err = s.Set(nil, req)
+
+	gotRetStatus, ok := status.FromError(err)
+	if !ok {
+		t.Fatal("got a non-grpc error from grpc call")
+	}
+	if gotRetStatus.Code() != codes.FailedPrecondition {
+		t.Fatalf("got return code %v, want %v\nerror message: %v", gotRetStatus.Code(), codes.FailedPrecondition, err)
+	}
+	if got := s.config.(*oc.Device).GetInterface("eth-1/1").GetDescription(); got != "uplink" {
+		t.Errorf("got description %q after rejected Set, want %q", got, "uplink")
+	}
+}
+
+func TestSetOrigin(t *testing.T) {
+	initConfig := `{
+		"system": {
+			"config": {
+				"hostname": "switch_a"
+			}
+		},
+		"management": {
+			"transaction": {
+				"commit-confirm-timeout": 30
+			}
+		}
+	}`
+	domainNameVal := &pb.TypedValue{Value: &pb.TypedValue_StringVal{StringVal: "foo.bar.com"}}
+	domainNamePath := &pb.Path{Elem: []*pb.PathElem{{Name: "system"}, {Name: "config"}, {Name: "domain-name"}}}
+	cliVal := &pb.TypedValue{Value: &pb.TypedValue_AsciiVal{AsciiVal: "set system domain-name foo.bar.com"}}
+
+	tests := []struct {
+		desc        string
+		req         *pb.SetRequest
+		wantRetCode codes.Code
+		wantConfig  string
+	}{{
+		desc: "update with openconfig origin",
+		req: &pb.SetRequest{Update: []*pb.Update{{
+			Path: &pb.Path{Origin: "openconfig", Elem: domainNamePath.Elem},
+			Val:  domainNameVal,
+		}}},
+		wantRetCode: codes.OK,
+		wantConfig: `{
+			"system": {"config": {"hostname": "switch_a", "domain-name": "foo.bar.com"}},
+			"management": {"transaction": {"commit-confirm-timeout": 30}}
+		}`,
+	}, {
+		desc: "update with unknown origin",
+		req: &pb.SetRequest{Update: []*pb.Update{{
+			Path: &pb.Path{Origin: "foo", Elem: domainNamePath.Elem},
+			Val:  domainNameVal,
+		}}},
+		wantRetCode: codes.InvalidArgument,
+		wantConfig:  initConfig,
+	}, {
+		desc: "update with cli origin",
+		req: &pb.SetRequest{Update: []*pb.Update{{
+			Path: &pb.Path{Origin: "cli"},
+			Val:  cliVal,
+		}}},
+		wantRetCode: codes.Unimplemented,
+		wantConfig:  initConfig,
+	}, {
+		desc: "replace with cli origin",
+		req: &pb.SetRequest{Replace: []*pb.Update{{
+			Path: &pb.Path{Origin: "cli"},
+			Val:  cliVal,
+		}}},
+		wantRetCode: codes.Unimplemented,
+		wantConfig:  initConfig,
+	}, {
+		desc: "union replace with cli origin",
+		req: &pb.SetRequest{UnionReplace: []*pb.Update{{
+			Path: &pb.Path{Origin: "cli"},
+			Val:  cliVal,
+		}}},
+		wantRetCode: codes.Unimplemented,
+		wantConfig:  initConfig,
+	}, {
+		desc: "union replace keeps management transaction",
+		req: &pb.SetRequest{UnionReplace: []*pb.Update{{
+			Path: domainNamePath,
+			Val:  domainNameVal,
+		}}},
+		wantRetCode: codes.OK,
+		wantConfig: `{
+			"system": {"config": {"domain-name": "foo.bar.com"}},
+			"management": {"transaction": {"commit-confirm-timeout": 30}}
+		}`,
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			s, err := NewServer(model, []byte(initConfig), nil, nil)
+			if err != nil {
+				t.Fatalf("error in creating server: %v", err)
+			}
+			_, err = s.Set(nil, tc.req)
+			if got := status.Code(err); got != tc.wantRetCode {
+				t.Fatalf("got return code %v, want %v\nerror message: %v", got, tc.wantRetCode, err)
+			}
+
+			wantConfigStruct, err := model.NewConfigStruct([]byte(tc.wantConfig))
+			if err != nil {
+				t.Fatalf("wantConfig data cannot be loaded as a config struct: %v", err)
+			}
+			wantConfigJSON, err := ygot.ConstructIETFJSON(wantConfigStruct, &ygot.RFC7951JSONConfig{})
+			if err != nil {
+				t.Fatalf("error in constructing IETF JSON tree from wanted config: %v", err)
+			}
+			gotConfigJSON, err := ygot.ConstructIETFJSON(s.config, &ygot.RFC7951JSONConfig{})
+			if err != nil {
+				t.Fatalf("error in constructing IETF JSON tree from server config: %v", err)
+			}
+			if !reflect.DeepEqual(gotConfigJSON, wantConfigJSON) {
+				t.Fatalf("got server config %v\nwant: %v", gotConfigJSON, wantConfigJSON)
+			}
+		})
+	}
+}
+
+func TestSetAtomic(t *testing.T) {
+	initConfig := `{
+		"system": {
+			"config": {
+				"hostname": "switch_a",
+				"domain-name": "foo.bar.com"
+			}
+		}
+	}`
+	hostnamePath := &pb.Path{Elem: []*pb.PathElem{{Name: "system"}, {Name: "config"}, {Name: "hostname"}}}
+	loginBannerPath := &pb.Path{Elem: []*pb.PathElem{{Name: "system"}, {Name: "config"}, {Name: "login-banner"}}}
+	req := &pb.SetRequest{
+		Delete: []*pb.Path{hostnamePath, loginBannerPath},
+		Update: []*pb.Update{{
+			Path: &pb.Path{Elem: []*pb.PathElem{{Name: "system"}, {Name: "config"}, {Name: "motd-banner"}}},
+			Val:  &pb.TypedValue{Value: &pb.TypedValue_StringVal{StringVal: "hello"}},
+		}, {
+			Path: &pb.Path{Elem: []*pb.PathElem{{Name: "system"}, {Name: "config"}, {Name: "foo"}}},
+			Val:  &pb.TypedValue{Value: &pb.TypedValue_StringVal{StringVal: "bar"}},
+		}},
+	}
+
+	tests := []struct {
+		desc        string
+		callbackErr error
+		req         *pb.SetRequest
+		wantRetCode codes.Code
+		wantConfig  string
+	}{{
+		desc:        "failed operation discards all of them",
+		req:         req,
+		wantRetCode: codes.NotFound,
+		wantConfig:  initConfig,
+	}, {
+		desc:        "failed callback leaves config intact",
+		callbackErr: errors.New("device failure"),
+		req:         &pb.SetRequest{Delete: req.Delete, Update: req.Update[:1]},
+		wantRetCode: codes.Aborted,
+		wantConfig:  initConfig,
+	}, {
+		desc:        "all operations applied",
+		req:         &pb.SetRequest{Delete: req.Delete, Update: req.Update[:1]},
+		wantRetCode: codes.OK,
+		wantConfig: `{
+			"system": {
+				"config": {
+					"domain-name": "foo.bar.com",
+					"motd-banner": "hello"
+				}
+			}
+		}`,
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			callbackCalls := 0
+			callback := func(config ygot.ValidatedGoStruct, userData interface{}) (*ApplyResult, error) {
+				callbackCalls++
+				return nil, tc.callbackErr
+			}
+			s, err := NewServer(model, []byte(initConfig), callback, nil)
+			if err != nil {
+				t.Fatalf("error in creating server: %v", err)
+			}
+			callbackCalls = 0
+
+			_, err = s.Set(nil, tc.req)
+			if got := status.Code(err); got != tc.wantRetCode {
+				t.Fatalf("got return code %v, want %v\nerror message: %v", got, tc.wantRetCode, err)
+			}
+			if tc.wantRetCode != codes.NotFound && callbackCalls != 1 {
+				t.Errorf("got %d calls of callback, want 1", callbackCalls)
+			}
+
+			wantConfigStruct, err := model.NewConfigStruct([]byte(tc.wantConfig))
+			if err != nil {
+				t.Fatalf("wantConfig data cannot be loaded as a config struct: %v", err)
+			}
+			wantConfigJSON, err := ygot.ConstructIETFJSON(wantConfigStruct, &ygot.RFC7951JSONConfig{})
+			if err != nil {
+				t.Fatalf("error in constructing IETF JSON tree from wanted config: %v", err)
+			}
+			gotConfigJSON, err := ygot.ConstructIETFJSON(s.config, &ygot.RFC7951JSONConfig{})
+			if err != nil {
+				t.Fatalf("error in constructing IETF JSON tree from server config: %v", err)
+			}
+			if !reflect.DeepEqual(gotConfigJSON, wantConfigJSON) {
+				t.Fatalf("got server config %v\nwant: %v", gotConfigJSON, wantConfigJSON)
+			}
+		})
+	}
+}
+
+func TestSetKeepsStateFromCallback(t *testing.T) {
+	initConfig := `{
+		"management": {
+			"transaction": {
+				"commit-confirm-timeout": 60
+			}
+		}
+	}`
+	callback := func(config ygot.ValidatedGoStruct, userData interface{}) (*ApplyResult, error) {
+		// Device completes committed config with history of commits
+		config.(*oc.Device).GetOrCreateManagement().GetOrCreateCommit(1).User = ygot.String("admin")
+		return nil, nil
+	}
+	s, err := NewServer(model, []byte(initConfig), callback, nil)
+	if err != nil {
+		t.Fatalf("error in creating server: %v", err)
+	}
+
+	req := &pb.SetRequest{
+		Update: []*pb.Update{{
+			Path: &pb.Path{Elem: []*pb.PathElem{{Name: "management"}, {Name: "transaction"}, {Name: "commit-confirm-timeout"}}},
+			Val:  &pb.TypedValue{Value: &pb.TypedValue_UintVal{UintVal: 30}},
+		}},
+	}
+	if _, err := s.Set(nil, req); err != nil {
+		t.Fatalf("got error %v, want nil", err)
+	}
+
+	if got := s.config.(*oc.Device).GetManagement().GetCommit(1).GetUser(); got != "admin" {
+		t.Errorf("got user %q of commit 1 in server config, want %q", got, "admin")
+	}
+}
+
+func TestSetCommitConfirmed(t *testing.T) {
+	initConfig := `{"system": {"config": {"hostname": "switch_a"}}}`
+	changedConfig := `{"system": {"config": {"hostname": "switch_b"}}}`
+	update := []*pb.Update{{
+		Path: &pb.Path{Elem: []*pb.PathElem{{Name: "system"}, {Name: "config"}, {Name: "hostname"}}},
+		Val:  &pb.TypedValue{Value: &pb.TypedValue_StringVal{StringVal: "switch_b"}},
+	}}
+	commitExt := func(id string, action interface{}) []*ext.Extension {
+		commit := &ext.Commit{Id: id}
+		switch a := action.(type) {
+		case *ext.CommitRequest:
+			commit.Action = &ext.Commit_Commit{Commit: a}
+		case *ext.CommitConfirm:
+			commit.Action = &ext.Commit_Confirm{Confirm: a}
+		case *ext.CommitCancel:
+			commit.Action = &ext.Commit_Cancel{Cancel: a}
+		}
+		return []*ext.Extension{{Ext: &ext.Extension_Commit{Commit: commit}}}
+	}
+	commitReq := &pb.SetRequest{
+		Update:    update,
+		Extension: commitExt("c1", &ext.CommitRequest{RollbackDuration: durationpb.New(time.Minute)}),
+	}
+
+	tests := []struct {
+		desc        string
+		noCallback  bool
+		reqs        []*pb.SetRequest
+		wantRetCode codes.Code
+		wantActions []CommitAction
+		wantConfig  string
+	}{{
+		desc:        "commit without callback",
+		noCallback:  true,
+		reqs:        []*pb.SetRequest{commitReq},
+		wantRetCode: codes.Unimplemented,
+		wantConfig:  initConfig,
+	}, {
+		desc:        "commit awaiting confirmation",
+		reqs:        []*pb.SetRequest{commitReq},
+		wantRetCode: codes.OK,
+		wantActions: []CommitAction{CommitActionCommit},
+		wantConfig:  changedConfig,
+	}, {
+		desc:        "confirm commit",
+		reqs:        []*pb.SetRequest{commitReq, {Extension: commitExt("c1", &ext.CommitConfirm{})}},
+		wantRetCode: codes.OK,
+		wantActions: []CommitAction{CommitActionCommit, CommitActionConfirm},
+		wantConfig:  changedConfig,
+	}, {
+		desc:        "cancel commit",
+		reqs:        []*pb.SetRequest{commitReq, {Extension: commitExt("c1", &ext.CommitCancel{})}},
+		wantRetCode: codes.OK,
+		wantActions: []CommitAction{CommitActionCommit, CommitActionCancel},
+		wantConfig:  initConfig,
+	}, {
+		desc:        "confirm commit of other ID",
+		reqs:        []*pb.SetRequest{commitReq, {Extension: commitExt("c2", &ext.CommitConfirm{})}},
+		wantRetCode: codes.FailedPrecondition,
+		wantActions: []CommitAction{CommitActionCommit},
+		wantConfig:  changedConfig,
+	}, {
+		desc:        "commit while other one is pending",
+		reqs:        []*pb.SetRequest{commitReq, commitReq},
+		wantRetCode: codes.FailedPrecondition,
+		wantActions: []CommitAction{CommitActionCommit},
+		wantConfig:  changedConfig,
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			s, err := NewServer(model, []byte(initConfig), nil, nil)
+			if err != nil {
+				t.Fatalf("error in creating server: %v", err)
+			}
+			var gotActions []CommitAction
+			if !tc.noCallback {
+				s.SetCommitCallback(func(req *CommitRequest, newConfig ygot.ValidatedGoStruct, userData interface{}) error {
+					gotActions = append(gotActions, req.Action)
+					return nil
+				})
+			}
+
+			for _, req := range tc.reqs {
+				_, err = s.Set(nil, req)
+			}
+			if got := status.Code(err); got != tc.wantRetCode {
+				t.Fatalf("got return code %v, want %v\nerror message: %v", got, tc.wantRetCode, err)
+			}
+			if !reflect.DeepEqual(gotActions, tc.wantActions) {
+				t.Errorf("got commit actions %v, want %v", gotActions, tc.wantActions)
+			}
+
+			wantConfigStruct, err := model.NewConfigStruct([]byte(tc.wantConfig))
+			if err != nil {
+				t.Fatalf("wantConfig data cannot be loaded as a config struct: %v", err)
+			}
+			wantConfigJSON, err := ygot.ConstructIETFJSON(wantConfigStruct, &ygot.RFC7951JSONConfig{})
+			if err != nil {
+				t.Fatalf("error in constructing IETF JSON tree from wanted config: %v", err)
+			}
+			gotConfigJSON, err := ygot.ConstructIETFJSON(s.config, &ygot.RFC7951JSONConfig{})
+			if err != nil {
+				t.Fatalf("error in constructing IETF JSON tree from server config: %v", err)
+			}
+			if !reflect.DeepEqual(gotConfigJSON, wantConfigJSON) {
+				t.Fatalf("got server config %v\nwant: %v", gotConfigJSON, wantConfigJSON)
+			}
+		})
+	}
+}
+
+func TestResumePendingCommit(t *testing.T) {
+	initConfig := `{"system": {"config": {"hostname": "switch_a"}}}`
+	changedConfig := `{"system": {"config": {"hostname": "switch_b"}}}`
+	cancelReq := &pb.SetRequest{
+		Extension: []*ext.Extension{{Ext: &ext.Extension_Commit{Commit: &ext.Commit{
+			Id:     "c1",
+			Action: &ext.Commit_Cancel{Cancel: &ext.CommitCancel{}},
+		}}}},
+	}
+
+	s, err := NewServer(model, []byte(initConfig), nil, nil)
+	if err != nil {
+		t.Fatalf("error in creating server: %v", err)
+	}
+	s.SetCommitCallback(func(req *CommitRequest, newConfig ygot.ValidatedGoStruct, userData interface{}) error {
+		return nil
+	})
+	commitConfig, err := model.NewConfigStruct([]byte(changedConfig))
+	if err != nil {
+		t.Fatalf("changedConfig data cannot be loaded as a config struct: %v", err)
+	}
+	rollbackConfig, err := model.NewConfigStruct([]byte(initConfig))
+	if err != nil {
+		t.Fatalf("initConfig data cannot be loaded as a config struct: %v", err)
+	}
+	if err := s.ResumePendingCommit("c1", commitConfig, rollbackConfig); err != nil {
+		t.Fatalf("error in resuming pending commit: %v", err)
+	}
+	if !reflect.DeepEqual(s.config, commitConfig) {
+		t.Errorf("got server config %v, want config awaiting confirmation %v", s.config, commitConfig)
+	}
+
+	if _, err := s.Set(nil, cancelReq); err != nil {
+		t.Fatalf("got error %v in cancelling resumed commit, want nil", err)
+	}
+	if !reflect.DeepEqual(s.config, rollbackConfig) {
+		t.Errorf("got server config %v, want config before commit %v", s.config, rollbackConfig)
+	}
+}
+
+func TestSetDryRun(t *testing.T) {
+	initConfig := `{"system": {"config": {"hostname": "switch_a"}}}`
+	req := &pb.SetRequest{
+		Update: []*pb.Update{{
+			Path: &pb.Path{Elem: []*pb.PathElem{{Name: "system"}, {Name: "config"}, {Name: "hostname"}}},
+			Val:  &pb.TypedValue{Value: &pb.TypedValue_StringVal{StringVal: "switch_b"}},
+		}},
+	}
+	callback := func(config ygot.ValidatedGoStruct, userData interface{}) (*ApplyResult, error) {
+		return &ApplyResult{DryRun: &DryRunResult{
+			Commands:    []DryRunCommand{{Name: "set-hostname", Phase: 3}},
+			ChangePaths: [][]string{{"System", "Config", "Hostname"}},
+			Diff:        "hostname: switch_a -> switch_b",
+		}}, nil
+	}
+	s, err := NewServer(model, []byte(initConfig), callback, nil)
+	if err != nil {
+		t.Fatalf("error in creating server: %v", err)
+	}
+	wantConfig := s.config
+
+	resp, err := s.Set(nil, req)
+	if err != nil {
+		t.Fatalf("got error %v, want nil", err)
+	}
+	if s.config != wantConfig {
+		t.Errorf("dry run changed server config")
+	}
+	if len(resp.GetExtension()) != 1 {
+		t.Fatalf("got %d extensions of response, want 1", len(resp.GetExtension()))
+	}
+	msg, err := experimentalExtensionMsg(resp.GetExtension()[0], ExperimentalExtensionDryRunPlan)
+	if err != nil || msg == nil {
+		t.Fatalf("got message %q, error %v of extension, want plan of dry run", msg, err)
+	}
+
+	var gotPlan DryRunPlan
+	if err := json.Unmarshal(msg, &gotPlan); err != nil {
+		t.Fatalf("error in unmarshaling plan of dry run: %v", err)
+	}
+	wantPlan := DryRunPlan{
+		Commands: []DryRunCommand{{Name: "set-hostname", Phase: 3}},
+		Paths:    []string{"/system/config/hostname"},
+		Diff:     "hostname: switch_a -> switch_b",
+	}
+	if !reflect.DeepEqual(gotPlan, wantPlan) {
+		t.Errorf("got plan %+v, want %+v", gotPlan, wantPlan)
+	}
+}
+
+func TestSetBestEffort(t *testing.T) {
+	initConfig := `{"interfaces": {"interface": [
+		{"name": "eth-1/1", "config": {"name": "eth-1/1"}},
+		{"name": "eth-1/2", "config": {"name": "eth-1/2"}}
+	]}}`
+	descriptionPath := func(ifname string) *pb.Path {
+		return &pb.Path{Elem: []*pb.PathElem{
+			{Name: "interfaces"}, {Name: "interface", Key: map[string]string{"name": ifname}}, {Name: "config"}, {Name: "description"},
+		}}
+	}
+	req := &pb.SetRequest{
+		Update: []*pb.Update{{
+			Path: descriptionPath("eth-1/1"),
+			Val:  &pb.TypedValue{Value: &pb.TypedValue_StringVal{StringVal: "uplink"}},
+		}, {
+			Path: descriptionPath("eth-1/2"),
+			Val:  &pb.TypedValue{Value: &pb.TypedValue_StringVal{StringVal: "downlink"}},
+		}},
+	}
+	wantResult := BestEffortResult{
+		Groups: []CommitGroupResult{
+			{Interfaces: []string{"eth-1/1"}, Commands: []string{"set-description"}},
+			{Interfaces: []string{"eth-1/2"}, Commands: []string{"set-description"}, Error: "device failure"},
+		},
+	}
+	callback := func(config ygot.ValidatedGoStruct, userData interface{}) (*ApplyResult, error) {
+		// Failed changes are withdrawn from candidate
+		config.(*oc.Device).GetInterface("eth-1/2").Description = nil
+		result := wantResult
+		return &ApplyResult{BestEffort: &result}, nil
+	}
+	s, err := NewServer(model, []byte(initConfig), callback, nil)
+	if err != nil {
+		t.Fatalf("error in creating server: %v", err)
+	}
+
+	resp, err := s.Set(nil, req)
+	if err != nil {
+		t.Fatalf("got error %v, want nil", err)
+	}
+	device := s.config.(*oc.Device)
+	if got := device.GetInterface("eth-1/1").GetDescription(); got != "uplink" {
+		t.Errorf("got description %q of eth-1/1, want applied %q", got, "uplink")
+	}
+	if got := device.GetInterface("eth-1/2").GetDescription(); got != "" {
+		t.Errorf("got description %q of eth-1/2, want withdrawn one", got)
+	}
+	if len(resp.GetExtension()) != 1 {
+		t.Fatalf("got %d extensions of response, want 1", len(resp.GetExtension()))
+	}
+	msg, err := experimentalExtensionMsg(resp.GetExtension()[0], ExperimentalExtensionBestEffortReport)
+	if err != nil || msg == nil {
+		t.Fatalf("got message %q, error %v of extension, want report of best-effort commit", msg, err)
+	}
+
+	var gotResult BestEffortResult
+	if err := json.Unmarshal(msg, &gotResult); err != nil {
+		t.Fatalf("error in unmarshaling report of best-effort commit: %v", err)
+	}
+	if !reflect.DeepEqual(gotResult, wantResult) {
+		t.Errorf("got report %+v, want %+v", gotResult, wantResult)
+	}
+}
+
+func TestSetCandidate(t *testing.T) {
+	initConfig := `{"interfaces": {"interface": [
+		{"name": "eth-1/1", "config": {"name": "eth-1/1", "description": "uplink"}},
+		{"name": "eth-1/2", "config": {"name": "eth-1/2"}}
+	]}}`
+	s, err := NewServer(model, []byte(initConfig), nil, nil)
+	if err != nil {
+		t.Fatalf("error in creating server: %v", err)
+	}
+	candidatePrefix := &pb.Path{Target: CandidateTargetPrefix + "test"}
+	candidateAction := func(action CandidateAction, merge bool) *pb.SetRequest {
+		candidateExt, err := newExperimentalExtension(ExperimentalExtensionCandidateRequest, &CandidateRequest{Action: action, Merge: merge})
+		if err != nil {
+			t.Fatalf("error in marshaling candidate request: %v", err)
+		}
+		return &pb.SetRequest{
+			Prefix:    candidatePrefix,
+			Extension: []*ext.Extension{candidateExt},
+		}
+	}
+	descriptionPath := func(ifname string) *pb.Path {
+		return &pb.Path{Elem: []*pb.PathElem{
+			{Name: "interfaces"}, {Name: "interface", Key: map[string]string{"name": ifname}}, {Name: "config"}, {Name: "description"},
+		}}
+	}
+
+	// Edit candidate, while config of the server stays intact
+	if _, err := s.Set(nil, &pb.SetRequest{
+		Prefix: candidatePrefix,
+		Update: []*pb.Update{{Path: descriptionPath("eth-1/1"), Val: &pb.TypedValue{Value: &pb.TypedValue_StringVal{StringVal: "core"}}}},
+	}); err != nil {
+		t.Fatalf("got error %v in changing candidate, want nil", err)
+	}
+	if got := s.config.(*oc.Device).GetInterface("eth-1/1").GetDescription(); got != "uplink" {
+		t.Errorf("got description %q of server config, want %q", got, "uplink")
+	}
+	resp, err := s.Get(nil, &pb.GetRequest{Prefix: candidatePrefix, Path: []*pb.Path{descriptionPath("eth-1/1")}, Encoding: pb.Encoding_JSON_IETF})
+	if err != nil {
+		t.Fatalf("got error %v in getting candidate, want nil", err)
+	}
+	if got := resp.GetNotification()[0].GetUpdate()[0].GetVal().GetStringVal(); got != "core" {
+		t.Errorf("got description %q of candidate, want %q", got, "core")
+	}
+
+	// Diff candidate with config of the server
+	resp2, err := s.Set(nil, candidateAction(CandidateActionDiff, false))
+	if err != nil {
+		t.Fatalf("got error %v in getting diff of candidate, want nil", err)
+	}
+	msg, err := experimentalExtensionMsg(resp2.GetExtension()[0], ExperimentalExtensionCandidateDiff)
+	if err != nil || msg == nil {
+		t.Fatalf("got message %q, error %v of extension, want diff of candidate", msg, err)
+	}
+	var gotDiff CandidateDiff
+	if err := json.Unmarshal(msg, &gotDiff); err != nil {
+		t.Fatalf("error in unmarshaling diff of candidate: %v", err)
+	}
+	wantPath := "/interfaces/interface[name=eth-1/1]/config/description"
+	if len(gotDiff.Changes) != 1 || gotDiff.Changes[0].Path != wantPath || gotDiff.Stale {
+		t.Errorf("got diff of candidate %+v, want single change of %s", gotDiff, wantPath)
+	}
+
+	// Config of the server changes underneath candidate
+	if _, err := s.Set(nil, &pb.SetRequest{
+		Update: []*pb.Update{{Path: descriptionPath("eth-1/2"), Val: &pb.TypedValue{Value: &pb.TypedValue_StringVal{StringVal: "downlink"}}}},
+	}); err != nil {
+		t.Fatalf("got error %v in changing server config, want nil", err)
+	}
+	_, err = s.Set(nil, candidateAction(CandidateActionCommit, false))
+	if got := status.Code(err); got != codes.FailedPrecondition {
+		t.Fatalf("got return code %v of commit of stale candidate, want %v", got, codes.FailedPrecondition)
+	}
+
+	// Merge keeps changes of both
+	if _, err := s.Set(nil, candidateAction(CandidateActionCommit, true)); err != nil {
+		t.Fatalf("got error %v in commit of candidate with merge, want nil", err)
+	}
+	device := s.config.(*oc.Device)
+	if got := device.GetInterface("eth-1/1").GetDescription(); got != "core" {
+		t.Errorf("got description %q of eth-1/1 after commit of candidate, want %q", got, "core")
+	}
+	if got := device.GetInterface("eth-1/2").GetDescription(); got != "downlink" {
+		t.Errorf("got description %q of eth-1/2 after commit of candidate, want %q", got, "downlink")
+	}
+	if _, err := s.Set(nil, candidateAction(CandidateActionDiscard, false)); status.Code(err) != codes.NotFound {
+		t.Errorf("got return code %v in discarding committed candidate, want %v", status.Code(err), codes.NotFound)
+	}
+}
+
+func TestSetCandidateLimits(t *testing.T) {
+	s, err := NewServer(model, []byte(`{"system": {"config": {"hostname": "switch_a"}}}`), nil, nil)
+	if err != nil {
+		t.Fatalf("error in creating server: %v", err)
+	}
+	user := "alice"
+	s.SetRequestUserFunc(func(ctx context.Context) string { return user })
+	createCandidate := func(name string) error {
+		_, err := s.Set(nil, &pb.SetRequest{
+			Prefix: &pb.Path{Target: CandidateTargetPrefix + name},
+			Update: []*pb.Update{{
+				Path: &pb.Path{Elem: []*pb.PathElem{{Name: "system"}, {Name: "config"}, {Name: "hostname"}}},
+				Val:  &pb.TypedValue{Value: &pb.TypedValue_StringVal{StringVal: name}},
+			}},
+		})
+		return err
+	}
+
+	for i := 0; i < maxCandidatesPerUser; i++ {
+		if err := createCandidate(fmt.Sprintf("c%d", i)); err != nil {
+			t.Fatalf("got error %v in creating candidate %d, want nil", err, i)
+		}
+	}
+	if err := createCandidate("c0"); err != nil {
+		t.Errorf("got error %v in changing existing candidate, want nil", err)
+	}
+	if err := createCandidate("extra"); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("got return code %v in creating candidate over limit of user, want %v", status.Code(err), codes.ResourceExhausted)
+	}
+
+	for i := maxCandidatesPerUser; i < maxCandidates; i++ {
+		user = fmt.Sprintf("user%d", i)
+		if err := createCandidate("c"); err != nil {
+			t.Fatalf("got error %v in creating candidate %d, want nil", err, i)
+		}
+	}
+	user = "bob"
+	if err := createCandidate("c"); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("got return code %v in creating candidate over total limit, want %v", status.Code(err), codes.ResourceExhausted)
+	}
+}
+
+func TestCandidateRequest(t *testing.T) {
+	planExt, err := newExperimentalExtension(ExperimentalExtensionDryRunPlan, &DryRunPlan{})
+	if err != nil {
+		t.Fatalf("error in marshaling plan of dry run: %v", err)
+	}
+	candidateExt, err := newExperimentalExtension(ExperimentalExtensionCandidateRequest, &CandidateRequest{Action: CandidateActionCommit})
+	if err != nil {
+		t.Fatalf("error in marshaling candidate request: %v", err)
+	}
+
+	tests := []struct {
+		desc        string
+		extensions  []*ext.Extension
+		wantReq     *CandidateRequest
+		wantRetCode codes.Code
+	}{{
+		desc:       "experimental extension of other type",
+		extensions: []*ext.Extension{planExt},
+	}, {
+		desc:       "candidate request among other extensions",
+		extensions: []*ext.Extension{planExt, candidateExt},
+		wantReq:    &CandidateRequest{Action: CandidateActionCommit},
+	}, {
+		desc:        "more than one candidate request",
+		extensions:  []*ext.Extension{candidateExt, candidateExt},
+		wantRetCode: codes.InvalidArgument,
+	}, {
+		desc: "experimental extension without envelope",
+		extensions: []*ext.Extension{{
+			Ext: &ext.Extension_RegisteredExt{
+				RegisteredExt: &ext.RegisteredExtension{Id: ExperimentalExtensionID, Msg: []byte(`{"action": "commit"`)},
+			},
+		}},
+		wantRetCode: codes.InvalidArgument,
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			gotReq, err := candidateRequest(tc.extensions)
+			if status.Code(err) != tc.wantRetCode {
+				t.Fatalf("got error code %v, want %v", status.Code(err), tc.wantRetCode)
+			}
+			if !reflect.DeepEqual(gotReq, tc.wantReq) {
+				t.Errorf("got candidate request %+v, want %+v", gotReq, tc.wantReq)
+			}
+		})
+	}
+}
@@ -0,0 +1,319 @@
+package gnmi
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	log "github.com/golang/glog"
+	"github.com/golang/protobuf/proto"
+
+	"github.com/openconfig/ygot/ygot"
+	"github.com/r3labs/diff"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+var (
+	// defaultSampleInterval is used by SAMPLE subscriptions which do not specify sample_interval.
+	defaultSampleInterval = 10 * time.Second
+	// minSampleInterval is the lowest sample_interval accepted from a client.
+	minSampleInterval = time.Second
+	// maxPendingChanges defines how many config change notifications can wait
+	// for sending to a single subscriber before its stream is closed.
+	maxPendingChanges = 256
+
+	syncResponse = &pb.SubscribeResponse{Response: &pb.SubscribeResponse_SyncResponse{SyncResponse: true}}
+)
+
+// subscriber maintains the state of single Subscribe RPC.
+type subscriber struct {
+	stream   pb.GNMI_SubscribeServer
+	list     *pb.SubscriptionList
+	sendMu   sync.Mutex            // sendMu serializes sending over stream
+	onChange []*pb.Subscription    // subscriptions notified about config changes
+	changes  chan *pb.Notification // config change notifications waiting for sending
+	errC     chan error            // errC terminates stream processing
+}
+
+func newSubscriber(stream pb.GNMI_SubscribeServer, list *pb.SubscriptionList) *subscriber {
+	return &subscriber{
+		stream:  stream,
+		list:    list,
+		changes: make(chan *pb.Notification, maxPendingChanges),
+		errC:    make(chan error, 1),
+	}
+}
+
+// send sends a single response to the client. It is safe to call it from many goroutines.
+func (c *subscriber) send(resp *pb.SubscribeResponse) error {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	return c.stream.Send(resp)
+}
+
+func (c *subscriber) sendNotification(n *pb.Notification) error {
+	return c.send(&pb.SubscribeResponse{Response: &pb.SubscribeResponse_Update{Update: n}})
+}
+
+// queueChange passes a notification about config change to the goroutine serving
+// the stream. It never blocks, a subscriber which does not keep up is closed.
+func (c *subscriber) queueChange(n *pb.Notification) {
+	select {
+	case c.changes <- n:
+	default:
+		c.fail(status.Error(codes.ResourceExhausted, "subscriber is too slow to receive config changes"))
+	}
+}
+
+func (c *subscriber) fail(err error) {
+	select {
+	case c.errC <- err:
+	default:
+	}
+}
+
+// Subscribe implements the Subscribe RPC in gNMI spec.
+func (s *Server) Subscribe(stream pb.GNMI_SubscribeServer) error {
+	req, err := stream.Recv()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	list := req.GetSubscribe()
+	if list == nil {
+		return status.Errorf(codes.InvalidArgument, "first request must contain a subscription list, got: %v", req)
+	}
+	if err := s.checkEncodingAndModel(list.GetEncoding(), list.GetUseModels()); err != nil {
+		return status.Error(codes.Unimplemented, err.Error())
+	}
+
+	c := newSubscriber(stream, list)
+	switch list.GetMode() {
+	case pb.SubscriptionList_ONCE:
+		return s.sendSnapshot(c, list.GetSubscription(), list.GetUpdatesOnly())
+	case pb.SubscriptionList_POLL:
+		return s.processPollSubscription(c)
+	case pb.SubscriptionList_STREAM:
+		return s.processStreamSubscription(c)
+	default:
+		return status.Errorf(codes.InvalidArgument, "unsupported subscription mode: %v", list.GetMode())
+	}
+}
+
+// sampleNotification returns the current value of the node pointed by subscription.
+// Nil notification is returned if the node does not exist. Caller must hold s.mu.
+func (s *Server) sampleNotification(c *subscriber, sub *pb.Subscription) (*pb.Notification, error) {
+	n, err := s.getNotification(s.config, c.list.GetPrefix(), sub.GetPath(), pb.GetRequest_ALL, c.list.GetEncoding(), c.list.GetUseModels())
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return n, nil
+}
+
+// sendSnapshot sends values of all subscribed nodes followed by sync_response.
+func (s *Server) sendSnapshot(c *subscriber, subs []*pb.Subscription, updatesOnly bool) error {
+	if !updatesOnly {
+		s.mu.RLock()
+		notifications := make([]*pb.Notification, 0, len(subs))
+		for _, sub := range subs {
+			n, err := s.sampleNotification(c, sub)
+			if err != nil {
+				s.mu.RUnlock()
+				return err
+			}
+			if n != nil {
+				notifications = append(notifications, n)
+			}
+		}
+		s.mu.RUnlock()
+
+		for _, n := range notifications {
+			if err := c.sendNotification(n); err != nil {
+				return err
+			}
+		}
+	}
+	return c.send(syncResponse)
+}
+
+// processPollSubscription handles the POLL mode of Subscribe RPC. Every poll
+// request received from the client triggers sending of subscribed nodes again.
+func (s *Server) processPollSubscription(c *subscriber) error {
+	if err := s.sendSnapshot(c, c.list.GetSubscription(), c.list.GetUpdatesOnly()); err != nil {
+		return err
+	}
+	for {
+		req, err := c.stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if req.GetPoll() == nil {
+			return status.Errorf(codes.InvalidArgument, "expected poll request, got: %v", req)
+		}
+		if err := s.sendSnapshot(c, c.list.GetSubscription(), false); err != nil {
+			return err
+		}
+	}
+}
+
+// processStreamSubscription handles the STREAM mode of Subscribe RPC. SAMPLE
+// subscriptions are served periodically, ON_CHANGE and TARGET_DEFINED ones are
+// served every time the config is changed.
+func (s *Server) processStreamSubscription(c *subscriber) error {
+	var samples []*pb.Subscription
+	for _, sub := range c.list.GetSubscription() {
+		switch sub.GetMode() {
+		case pb.SubscriptionMode_SAMPLE:
+			samples = append(samples, sub)
+		case pb.SubscriptionMode_ON_CHANGE, pb.SubscriptionMode_TARGET_DEFINED:
+			c.onChange = append(c.onChange, sub)
+		default:
+			return status.Errorf(codes.InvalidArgument, "unsupported subscription mode: %v", sub.GetMode())
+		}
+	}
+
+	// Register before the initial walk, so no config change can be missed in between
+	s.addSubscriber(c)
+	defer s.removeSubscriber(c)
+
+	if err := s.sendSnapshot(c, c.list.GetSubscription(), c.list.GetUpdatesOnly()); err != nil {
+		return err
+	}
+
+	ctx := c.stream.Context()
+	for _, sub := range samples {
+		go s.runSampleSubscription(ctx, c, sub)
+	}
+	go func() {
+		// Client is not expected to send anything more in STREAM mode, just detect closing of stream
+		for {
+			if _, err := c.stream.Recv(); err != nil {
+				if err != io.EOF {
+					c.fail(err)
+				}
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-c.errC:
+			return err
+		case n := <-c.changes:
+			if err := c.sendNotification(n); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// runSampleSubscription sends value of subscribed node every sample interval
+// till the stream is closed.
+func (s *Server) runSampleSubscription(ctx context.Context, c *subscriber, sub *pb.Subscription) {
+	interval := defaultSampleInterval
+	if sub.GetSampleInterval() > 0 {
+		interval = time.Duration(sub.GetSampleInterval())
+		if interval < minSampleInterval {
+			interval = minSampleInterval
+		}
+	}
+	heartbeat := time.Duration(sub.GetHeartbeatInterval())
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var last *pb.Notification
+	lastSentAt := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		s.mu.RLock()
+		n, err := s.sampleNotification(c, sub)
+		s.mu.RUnlock()
+		if err != nil {
+			c.fail(err)
+			return
+		}
+		if n == nil {
+			continue
+		}
+		if sub.GetSuppressRedundant() && isSameNotification(n, last) {
+			if heartbeat == 0 || time.Since(lastSentAt) < heartbeat {
+				continue
+			}
+		}
+		if err := c.sendNotification(n); err != nil {
+			c.fail(err)
+			return
+		}
+		last = n
+		lastSentAt = time.Now()
+	}
+}
+
+func (s *Server) addSubscriber(c *subscriber) {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+	s.subscribers[c] = struct{}{}
+}
+
+func (s *Server) removeSubscriber(c *subscriber) {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+	delete(s.subscribers, c)
+}
+
+// PublishChangelog notifies ON_CHANGE subscribers about changes of config
+// committed to the device. The changelog has to be made against newConfig,
+// which is the config after the changes.
+func (s *Server) PublishChangelog(changelog diff.Changelog, newConfig ygot.GoStruct) error {
+	if len(changelog) == 0 {
+		return nil
+	}
+	n, err := s.model.changelogNotification(changelog, newConfig)
+	if err != nil {
+		log.Errorf("failed to convert changelog into notification: %v", err)
+		return err
+	}
+
+	s.subMu.RLock()
+	defer s.subMu.RUnlock()
+	for c := range s.subscribers {
+		if cn := c.filterChanges(n); cn != nil {
+			c.queueChange(cn)
+		}
+	}
+	return nil
+}
+
+// isSameNotification checks if both notifications carry the same updates and
+// deletes, regardless of their timestamps.
+func isSameNotification(a, b *pb.Notification) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	ac := proto.Clone(a).(*pb.Notification)
+	bc := proto.Clone(b).(*pb.Notification)
+	ac.Timestamp, bc.Timestamp = 0, 0
+	return proto.Equal(ac, bc)
+}
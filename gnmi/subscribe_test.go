@@ -0,0 +1,229 @@
+package gnmi
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+const subscribeTestConfig = `{
+	"openconfig-interfaces:interfaces": {
+		"interface": [
+			{
+				"name": "eth-1",
+				"config": {
+					"name": "eth-1",
+					"mtu": 1500
+				}
+			}
+		]
+	}
+}`
+
+// fakeSubscribeStream implements pb.GNMI_SubscribeServer on top of channels.
+type fakeSubscribeStream struct {
+	grpc.ServerStream
+	ctx   context.Context
+	reqs  chan *pb.SubscribeRequest
+	resps chan *pb.SubscribeResponse
+}
+
+func newFakeSubscribeStream(ctx context.Context) *fakeSubscribeStream {
+	return &fakeSubscribeStream{
+		ctx:   ctx,
+		reqs:  make(chan *pb.SubscribeRequest, 8),
+		resps: make(chan *pb.SubscribeResponse, 64),
+	}
+}
+
+func (f *fakeSubscribeStream) Context() context.Context {
+	return f.ctx
+}
+
+func (f *fakeSubscribeStream) Send(resp *pb.SubscribeResponse) error {
+	f.resps <- resp
+	return nil
+}
+
+func (f *fakeSubscribeStream) Recv() (*pb.SubscribeRequest, error) {
+	select {
+	case req, ok := <-f.reqs:
+		if !ok {
+			return nil, io.EOF
+		}
+		return req, nil
+	case <-f.ctx.Done():
+		return nil, f.ctx.Err()
+	}
+}
+
+func (f *fakeSubscribeStream) next(t *testing.T) *pb.SubscribeResponse {
+	select {
+	case resp := <-f.resps:
+		return resp
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for subscribe response")
+	}
+	return nil
+}
+
+func mtuSubscriptionList(t *testing.T, mode pb.SubscriptionList_Mode, subMode pb.SubscriptionMode) *pb.SubscriptionList {
+	var prefix, path pb.Path
+	if err := proto.UnmarshalText(`
+		elem: <name: "interfaces" >
+		elem: <
+			name: "interface"
+			key: <key: "name" value: "eth-1" >
+		>`, &prefix); err != nil {
+		t.Fatalf("error in unmarshaling prefix: %v", err)
+	}
+	if err := proto.UnmarshalText(`
+		elem: <name: "config" >
+		elem: <name: "mtu" >`, &path); err != nil {
+		t.Fatalf("error in unmarshaling path: %v", err)
+	}
+	return &pb.SubscriptionList{
+		Prefix:       &prefix,
+		Mode:         mode,
+		Encoding:     pb.Encoding_JSON_IETF,
+		Subscription: []*pb.Subscription{{Path: &path, Mode: subMode}},
+	}
+}
+
+func checkMtuUpdate(t *testing.T, resp *pb.SubscribeResponse, want uint64) {
+	n := resp.GetUpdate()
+	if n == nil {
+		t.Fatalf("got %v, want notification", resp)
+	}
+	if len(n.GetUpdate()) != 1 {
+		t.Fatalf("got %d updates in the notification, want 1", len(n.GetUpdate()))
+	}
+	if got := n.GetUpdate()[0].GetVal().GetUintVal(); got != want {
+		t.Errorf("got MTU %d, want %d", got, want)
+	}
+}
+
+func checkSyncResponse(t *testing.T, resp *pb.SubscribeResponse) {
+	if !resp.GetSyncResponse() {
+		t.Fatalf("got %v, want sync_response", resp)
+	}
+}
+
+func TestSubscribeOnce(t *testing.T) {
+	s, err := NewServer(model, []byte(subscribeTestConfig), nil, nil)
+	if err != nil {
+		t.Fatalf("error in creating server: %v", err)
+	}
+
+	stream := newFakeSubscribeStream(context.Background())
+	stream.reqs <- &pb.SubscribeRequest{Request: &pb.SubscribeRequest_Subscribe{
+		Subscribe: mtuSubscriptionList(t, pb.SubscriptionList_ONCE, pb.SubscriptionMode_TARGET_DEFINED),
+	}}
+	if err := s.Subscribe(stream); err != nil {
+		t.Fatalf("got error %v, want nil", err)
+	}
+
+	checkMtuUpdate(t, stream.next(t), 1500)
+	checkSyncResponse(t, stream.next(t))
+}
+
+func TestSubscribePoll(t *testing.T) {
+	s, err := NewServer(model, []byte(subscribeTestConfig), nil, nil)
+	if err != nil {
+		t.Fatalf("error in creating server: %v", err)
+	}
+
+	stream := newFakeSubscribeStream(context.Background())
+	stream.reqs <- &pb.SubscribeRequest{Request: &pb.SubscribeRequest_Subscribe{
+		Subscribe: mtuSubscriptionList(t, pb.SubscriptionList_POLL, pb.SubscriptionMode_TARGET_DEFINED),
+	}}
+	stream.reqs <- &pb.SubscribeRequest{Request: &pb.SubscribeRequest_Poll{Poll: &pb.Poll{}}}
+	close(stream.reqs)
+	if err := s.Subscribe(stream); err != nil {
+		t.Fatalf("got error %v, want nil", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		checkMtuUpdate(t, stream.next(t), 1500)
+		checkSyncResponse(t, stream.next(t))
+	}
+}
+
+func TestSubscribeStreamOnChange(t *testing.T) {
+	s, err := NewServer(model, []byte(subscribeTestConfig), nil, nil)
+	if err != nil {
+		t.Fatalf("error in creating server: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream := newFakeSubscribeStream(ctx)
+	list := mtuSubscriptionList(t, pb.SubscriptionList_STREAM, pb.SubscriptionMode_ON_CHANGE)
+	stream.reqs <- &pb.SubscribeRequest{Request: &pb.SubscribeRequest_Subscribe{Subscribe: list}}
+	errC := make(chan error, 1)
+	go func() {
+		errC <- s.Subscribe(stream)
+	}()
+
+	checkMtuUpdate(t, stream.next(t), 1500)
+	checkSyncResponse(t, stream.next(t))
+
+	setReq := &pb.SetRequest{
+		Prefix: list.GetPrefix(),
+		Update: []*pb.Update{{
+			Path: list.GetSubscription()[0].GetPath(),
+			Val:  &pb.TypedValue{Value: &pb.TypedValue_UintVal{UintVal: 9000}},
+		}},
+	}
+	if _, err := s.Set(nil, setReq); err != nil {
+		t.Fatalf("got error %v, want nil", err)
+	}
+	checkMtuUpdate(t, stream.next(t), 9000)
+
+	cancel()
+	if err := <-errC; err != nil {
+		t.Fatalf("got error %v, want nil", err)
+	}
+}
+
+func TestSubscribeStreamOnChangeDelete(t *testing.T) {
+	s, err := NewServer(model, []byte(subscribeTestConfig), nil, nil)
+	if err != nil {
+		t.Fatalf("error in creating server: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream := newFakeSubscribeStream(ctx)
+	list := mtuSubscriptionList(t, pb.SubscriptionList_STREAM, pb.SubscriptionMode_ON_CHANGE)
+	stream.reqs <- &pb.SubscribeRequest{Request: &pb.SubscribeRequest_Subscribe{Subscribe: list}}
+	errC := make(chan error, 1)
+	go func() {
+		errC <- s.Subscribe(stream)
+	}()
+
+	checkMtuUpdate(t, stream.next(t), 1500)
+	checkSyncResponse(t, stream.next(t))
+
+	if _, err := s.Set(nil, &pb.SetRequest{Delete: []*pb.Path{list.GetPrefix()}}); err != nil {
+		t.Fatalf("got error %v, want nil", err)
+	}
+	n := stream.next(t).GetUpdate()
+	if len(n.GetDelete()) != 1 {
+		t.Fatalf("got %v, want notification with a single delete", n)
+	}
+	if got, want := n.GetDelete()[0], list.GetSubscription()[0].GetPath(); !proto.Equal(got, want) {
+		t.Errorf("got deleted path %v, want %v", got, want)
+	}
+
+	cancel()
+	if err := <-errC; err != nil {
+		t.Fatalf("got error %v, want nil", err)
+	}
+}
@@ -139,6 +139,7 @@ func ConvertYgotGoStructIntoJsonByteStream(ygotStruct ygot.ValidatedGoStruct) ([
 	}
 
 	stripMgmtTransConfigAction(jsonTree)
+	stripMgmtCommits(jsonTree)
 	jsonDump, err := json.MarshalIndent(jsonTree, "", "  ")
 	if err != nil {
 		msg := fmt.Sprintf("error in marshaling %s JSON tree to bytes: %v", jsonType, err)
@@ -179,3 +180,15 @@ func stripMgmtTransConfigAction(jsonTree map[string]interface{}) {
 	transJsonTree := trans.(map[string]interface{})
 	delete(transJsonTree, "config-action") // No problem if "config-action" isn't in the map
 }
+
+// stripMgmtCommits removes history of commits and scheduled commits, which are
+// kept only in memory
+func stripMgmtCommits(jsonTree map[string]interface{}) {
+	mgmt, exists := jsonTree["management"]
+	if !exists {
+		return
+	}
+	mgmtJsonTree := mgmt.(map[string]interface{})
+	delete(mgmtJsonTree, "commits")
+	delete(mgmtJsonTree, "scheduled-commits")
+}
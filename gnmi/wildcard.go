@@ -0,0 +1,171 @@
+package gnmi
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/openconfig/goyang/pkg/yang"
+	"github.com/openconfig/ygot/ygot"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+const (
+	anyElemName   = "*"   // matches any single path element
+	anyLevelsName = "..." // matches any number of path elements
+	anyKeyValue   = "*"   // matches any value of list key
+)
+
+// isWildcardPath checks if path may match more than one node, i.e. it contains
+// wildcards or it misses keys of some list.
+func isWildcardPath(root *yang.Entry, path *pb.Path) bool {
+	schema := root
+	for _, elem := range path.GetElem() {
+		if elem.GetName() == anyElemName || elem.GetName() == anyLevelsName {
+			return true
+		}
+		for _, v := range elem.GetKey() {
+			if v == anyKeyValue {
+				return true
+			}
+		}
+		if schema == nil {
+			continue
+		}
+		schema = schema.Dir[elem.GetName()]
+		if schema != nil && schema.IsList() && len(elem.GetKey()) == 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// pathExpander resolves wildcard path into the paths of existing nodes.
+type pathExpander struct {
+	resolved []*pb.Path
+	seen     map[string]bool
+}
+
+// expandWildcardPath returns fully resolved paths of all nodes from config,
+// which match path with wildcards.
+func expandWildcardPath(config ygot.GoStruct, root *yang.Entry, path *pb.Path) ([]*pb.Path, error) {
+	e := &pathExpander{seen: make(map[string]bool)}
+	if err := e.expandStruct(reflect.ValueOf(config), root, path.GetElem(), nil); err != nil {
+		return nil, err
+	}
+
+	return e.resolved, nil
+}
+
+func (e *pathExpander) emit(elems []*pb.PathElem) {
+	path := &pb.Path{Elem: append([]*pb.PathElem{}, elems...)}
+	key := proto.CompactTextString(path)
+	if !e.seen[key] {
+		e.seen[key] = true
+		e.resolved = append(e.resolved, path)
+	}
+}
+
+// expandStruct matches pattern against children of GoStruct node placed at
+// resolved path.
+func (e *pathExpander) expandStruct(node reflect.Value, schema *yang.Entry, pattern, resolved []*pb.PathElem) error {
+	if isPatternEnd(pattern) {
+		e.emit(resolved)
+		return nil
+	}
+
+	structValue := node.Elem()
+	for i := 0; i < structValue.NumField(); i++ {
+		field := structValue.Type().Field(i)
+		if !isSchemaField(field) || isZeroLeaf(structValue.Field(i)) {
+			continue
+		}
+		for _, tag := range strings.Split(field.Tag.Get("path"), "|") {
+			if err := e.matchTag(structValue.Field(i), schema, strings.Split(tag, "/"), pattern, resolved); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// matchTag matches pattern against elements of compressed path tag of field.
+// Node of field is reached when all elements of tag have been consumed.
+func (e *pathExpander) matchTag(field reflect.Value, schema *yang.Entry, tag []string, pattern, resolved []*pb.PathElem) error {
+	if len(tag) == 0 {
+		if _, ok := field.Interface().(ygot.GoStruct); ok {
+			return e.expandStruct(field, schema, pattern, resolved)
+		}
+		if isPatternEnd(pattern) {
+			e.emit(resolved)
+		}
+		return nil
+	}
+	if isPatternEnd(pattern) {
+		// Pattern ends at container omitted from compressed GoStruct
+		if len(resolved) > 0 {
+			e.emit(resolved)
+		}
+		return nil
+	}
+
+	next, ok := schema.Dir[tag[0]]
+	if !ok {
+		return fmt.Errorf("schema node %q not found in %q", tag[0], schema.Name)
+	}
+	elem := pattern[0]
+	if elem.GetName() == anyLevelsName {
+		// Skip wildcard or let it consume the current element
+		if err := e.matchTag(field, schema, tag, pattern[1:], resolved); err != nil {
+			return err
+		}
+		return e.consumeTagElem(field, next, tag, elem, pattern, resolved)
+	}
+	if elem.GetName() != anyElemName && elem.GetName() != tag[0] {
+		return nil
+	}
+
+	return e.consumeTagElem(field, next, tag, elem, pattern[1:], resolved)
+}
+
+// consumeTagElem appends the first element of tag to resolved path. If it is
+// the last element of tag pointing to list, every entry with keys matching
+// elem is visited.
+func (e *pathExpander) consumeTagElem(field reflect.Value, schema *yang.Entry, tag []string, elem *pb.PathElem, pattern, resolved []*pb.PathElem) error {
+	if len(tag) > 1 || field.Kind() != reflect.Map {
+		resolved = append(resolved[:len(resolved):len(resolved)], &pb.PathElem{Name: tag[0]})
+		return e.matchTag(field, schema, tag[1:], pattern, resolved)
+	}
+
+	keyName := strings.TrimSpace(schema.Key)
+	if strings.Contains(keyName, " ") {
+		return fmt.Errorf("list %q with many keys is unsupported", schema.Name)
+	}
+	for _, key := range field.MapKeys() {
+		keyValue := fmt.Sprint(key.Interface())
+		if elem.GetName() != anyLevelsName {
+			if want, ok := elem.GetKey()[keyName]; ok && want != anyKeyValue && want != keyValue {
+				continue
+			}
+		}
+		entryResolved := append(resolved[:len(resolved):len(resolved)], &pb.PathElem{
+			Name: tag[0],
+			Key:  map[string]string{keyName: keyValue},
+		})
+		if err := e.expandStruct(field.MapIndex(key), schema, pattern, entryResolved); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isPatternEnd checks if all elements of pattern have been matched. Trailing
+// "..." matches the node itself, since its value carries the whole subtree.
+func isPatternEnd(pattern []*pb.PathElem) bool {
+	return len(pattern) == 0 || (len(pattern) == 1 && pattern[0].GetName() == anyLevelsName)
+}
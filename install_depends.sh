@@ -3,7 +3,7 @@ mkdir $GOPATH/src/github.com/golang
 cd $GOPATH/src/github.com/golang
 git clone https://github.com/golang/protobuf.git
 cd protobuf
-git checkout git checkout v1.4.0-rc.4 # v1.3.5
+git checkout v1.5.3
 go get ./...
 make install
 #####################
@@ -25,19 +25,25 @@ cd goyang && go get ./... && go build && go install
 mkdir $GOPATH/src/github.com/openconfig
 cd $GOPATH/src/github.com/openconfig
 git clone https://github.com/openconfig/gnmi.git
-cd gnmi && git checkout 58b1f73c2cbd && go get ./...
+cd gnmi && git checkout v0.10.0 && go get ./...
 ##############
 mkdir $GOPATH/src/google.golang.org
 cd $GOPATH/src/google.golang.org
 git clone https://github.com/protocolbuffers/protobuf-go.git
 mv protobuf-go protobuf
-cd protobuf && git checkout v1.20.1
+cd protobuf && git checkout v1.31.0
+######################
+mkdir $GOPATH/src/google.golang.org
+cd $GOPATH/src/google.golang.org
+git clone https://github.com/grpc/grpc-go.git
+mv grpc-go grpc
+cd grpc && git checkout v1.56.2 && go get ./...
 ######################
 mkdir $GOPATH/src/google.golang.org
 cd $GOPATH/src/google.golang.org
 git clone https://github.com/googleapis/go-genproto.git
 mv go-genproto genproto
-cd genproto && git checkout 0848e9f44c36 && go get ./...
+cd genproto && git checkout 782d3b101e98 && go get ./...
 ######################
 mkdir $GOPATH/src/github.com/abiosoft
 cd $GOPATH/src/github.com/abiosoft
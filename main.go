@@ -10,12 +10,14 @@ import (
 	"os"
 	"reflect"
 	"strings"
+	"sync"
 
 	"github.com/abiosoft/ishell"
 	log "github.com/golang/glog"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/reflection"
 	"google.golang.org/grpc/status"
 
@@ -28,6 +30,7 @@ import (
 
 	pb "github.com/openconfig/gnmi/proto/gnmi"
 	"github.com/openconfig/ygot/ygot"
+	"github.com/r3labs/diff"
 
 	cfg "opennos-mgmt/config"
 )
@@ -96,14 +99,16 @@ var (
 
 type server struct {
 	*gnmi.Server
+	configMngr *cfg.ConfigMngrT
+	setMu      sync.Mutex // setMu serializes Set requests, so config manager knows who requests changes
 }
 
-var gnmiCallback gnmi.ConfigCallback = func(newConfig ygot.ValidatedGoStruct, cbUserData interface{}) error {
+var gnmiCallback gnmi.ConfigCallback = func(newConfig ygot.ValidatedGoStruct, cbUserData interface{}) (*gnmi.ApplyResult, error) {
 	configMngr := cbUserData.(*cfg.ConfigMngrT)
 	changelog, err := configMngr.GetDiffRunningConfigWithCandidateConfig(&newConfig)
 	if err != nil {
 		log.Errorf("Failed to get diff of two config objects: %s", err)
-		return err
+		return nil, err
 	}
 
 	log.Infof("Number of changes: %d", len(changelog))
@@ -115,12 +120,35 @@ var gnmiCallback gnmi.ConfigCallback = func(newConfig ygot.ValidatedGoStruct, cb
 	}
 
 	if len(changelog) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	return configMngr.CommitChangelog(&changelog, &newConfig)
 }
 
+var gnmiCommitCallback gnmi.CommitCallback = func(req *gnmi.CommitRequest, newConfig ygot.ValidatedGoStruct, cbUserData interface{}) error {
+	configMngr := cbUserData.(*cfg.ConfigMngrT)
+	switch req.Action {
+	case gnmi.CommitActionCommit:
+		changelog, err := configMngr.GetDiffRunningConfigWithCandidateConfig(&newConfig)
+		if err != nil {
+			log.Errorf("Failed to get diff of two config objects: %s", err)
+			return err
+		}
+
+		log.Infof("Commit %q with %d changes awaiting confirmation in %s", req.ID, len(changelog), req.RollbackDuration)
+		return configMngr.CommitChangelogConfirmed(&changelog, &newConfig, req.ID, req.RollbackDuration)
+	case gnmi.CommitActionConfirm:
+		return configMngr.ConfirmCommit(req.ID)
+	case gnmi.CommitActionCancel:
+		return configMngr.CancelCommit(req.ID)
+	case gnmi.CommitActionSetRollbackDuration:
+		return configMngr.SetCommitRollbackDuration(req.ID, req.RollbackDuration)
+	default:
+		return fmt.Errorf("Unsupported commit action %d", req.Action)
+	}
+}
+
 func newServer(model *gnmi.Model, config []byte) (*server, error) {
 	configMngr := cfg.NewConfigMngrT()
 	err := configMngr.LoadConfig(model, config)
@@ -128,11 +156,37 @@ func newServer(model *gnmi.Model, config []byte) (*server, error) {
 		return nil, err
 	}
 
-	s, err := gnmi.NewServer(model, config, gnmiCallback, configMngr)
+	// Startup config may be replaced by commit awaiting confirmation before restart, so the gNMI
+	// server starts with config already applied on device instead
+	runningConfig, err := configMngr.GetRunningConfig()
 	if err != nil {
 		return nil, err
 	}
-	return &server{Server: s}, nil
+
+	s, err := gnmi.NewServer(model, runningConfig, gnmiCallback, configMngr)
+	if err != nil {
+		return nil, err
+	}
+
+	configMngr.SetChangelogNotifier(func(changelog diff.Changelog, runningConfig ygot.ValidatedGoStruct) {
+		if err := s.PublishChangelog(changelog, runningConfig); err != nil {
+			log.Errorf("Failed to publish changes of running config: %s", err)
+		}
+	})
+	configMngr.SetRunningConfigNotifier(func(runningConfig ygot.ValidatedGoStruct, isCurrent func() bool) {
+		if err := s.RestoreConfig(runningConfig, isCurrent); err != nil {
+			log.Errorf("Failed to restore running config replaced by device: %s", err)
+		}
+	})
+	s.SetCommitCallback(gnmiCommitCallback)
+	s.SetRequestUserFunc(credentials.RequestUser)
+	s.SetCandidateDiffCallback(configMngr.GetDiffConfigs)
+	if commitID, commitConfig, rollbackConfig, resumed := configMngr.GetResumedCommit(); resumed {
+		if err := s.ResumePendingCommit(commitID, commitConfig, rollbackConfig); err != nil {
+			return nil, err
+		}
+	}
+	return &server{Server: s, configMngr: configMngr}, nil
 }
 
 // Get overrides the Get func of gnmi.Target to provide user auth.
@@ -154,9 +208,25 @@ func (s *server) Set(ctx context.Context, req *pb.SetRequest) (*pb.SetResponse,
 		return nil, status.Error(codes.PermissionDenied, msg)
 	}
 	log.Infof("allowed a Set request: %v", msg)
+	s.setMu.Lock()
+	defer s.setMu.Unlock()
+	s.configMngr.SetTransSession(requestSession(ctx))
 	return s.Server.Set(ctx, req)
 }
 
+// requestSession identifies client session by authenticated user and address of gRPC peer.
+func requestSession(ctx context.Context) cfg.SessionT {
+	user := credentials.RequestUser(ctx)
+	session := cfg.SessionT{
+		User:    user,
+		IsAdmin: credentials.IsAdminUser(user),
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		session.Peer = p.Addr.String()
+	}
+	return session
+}
+
 func gNMIServerRun() {
 	model := gnmi.NewModel(modeldata.ModelData,
 		reflect.TypeOf((*oc.Device)(nil)),
@@ -22,6 +22,7 @@ import (
 	"flag"
 	"fmt"
 	"io/ioutil"
+	"strings"
 
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
@@ -37,6 +38,7 @@ var (
 	key            = flag.String("key", "", "Private key file.")
 	insecure       = flag.Bool("insecure", false, "Skip TLS validation.")
 	notls          = flag.Bool("notls", false, "Disable TLS validation. If true, no need to specify TLS related options.")
+	adminUsers     = flag.String("admin_users", "", "Comma separated list of users allowed to break lock of configuration held by others.")
 	authorizedUser = userCredentials{}
 	usernameKey    = "username"
 	passwordKey    = "password"
@@ -159,3 +161,30 @@ func AuthorizeUser(ctx context.Context) (string, bool) {
 	}
 	return fmt.Sprintf("not authorized with \"%s:%s\"", user[0], pass[0]), false
 }
+
+// RequestUser returns username carried by the context Metadata, or empty string
+// if there is no such one.
+func RequestUser(ctx context.Context) string {
+	headers, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	user, ok := headers[usernameKey]
+	if !ok || len(user) == 0 {
+		return ""
+	}
+	return user[0]
+}
+
+// IsAdminUser checks if user is one of administrators given by -admin_users.
+func IsAdminUser(user string) bool {
+	if user == "" {
+		return false
+	}
+	for _, admin := range strings.Split(*adminUsers, ",") {
+		if strings.TrimSpace(admin) == user {
+			return true
+		}
+	}
+	return false
+}